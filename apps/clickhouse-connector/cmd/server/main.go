@@ -9,8 +9,13 @@ import (
 
 	"api-retailers-nest/apps/clickhouse-connector/internal/clickhouse"
 	grpcserver "api-retailers-nest/apps/clickhouse-connector/internal/grpc"
+	"api-retailers-nest/apps/clickhouse-connector/internal/schema"
 )
 
+// schemaDir est le répertoire des fichiers YAML décrivant le mapping colonnes -> protobuf
+// pour chaque entité servie par le connecteur (cf. internal/schema).
+const schemaDir = "configs/schemas"
+
 func main() {
 	// Configurer le logger
 	log.SetFlags(log.LstdFlags | log.Lshortfile)
@@ -40,6 +45,9 @@ func main() {
 		grpcConfig.Host, grpcConfig.Port,
 		grpcConfig.MaxRecvMsgSize, grpcConfig.MaxSendMsgSize,
 		grpcConfig.EnableReflection, grpcConfig.EnableHealthCheck)
+	if grpcConfig.Reattach {
+		log.Println("Reattach mode enabled: listening on a random loopback port, connection info will be printed to stdout")
+	}
 
 	// Créer le client ClickHouse
 	log.Println("Connecting to ClickHouse...")
@@ -55,9 +63,17 @@ func main() {
 	}()
 	log.Println("Successfully connected to ClickHouse")
 
+	// Charger le registre de schémas (mapping colonnes ClickHouse -> champs protobuf)
+	log.Println("Loading entity schemas...")
+	schemas, err := schema.LoadRegistry(schemaDir)
+	if err != nil {
+		log.Fatalf("Failed to load entity schemas: %v", err)
+		os.Exit(1)
+	}
+
 	// Créer le serveur gRPC
 	log.Println("Creating gRPC server...")
-	grpcServer, err := grpcserver.NewServer(&grpcConfig, clickhouseClient)
+	grpcServer, err := grpcserver.NewServer(&grpcConfig, clickhouseClient, schemas)
 	if err != nil {
 		log.Fatalf("Failed to create gRPC server: %v", err)
 		os.Exit(1)
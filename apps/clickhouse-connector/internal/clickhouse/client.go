@@ -2,31 +2,30 @@ package clickhouse
 
 import (
 	"context"
-	"crypto/tls"
 	"database/sql"
 	"fmt"
 	"log"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/ClickHouse/clickhouse-go/v2"
 	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
 )
 
-// Client gère la connexion à ClickHouse
+// Client gère la connexion à ClickHouse. conn/db sont protégés par mu car le keepalive
+// en arrière-plan peut les reconstruire pendant qu'un appel est en cours.
 type Client struct {
+	mu     sync.RWMutex
 	conn   driver.Conn
 	db     *sql.DB
 	config *Config
-}
 
-// NewClient crée un nouveau client ClickHouse
-func NewClient(config *Config) (*Client, error) {
-	if err := config.Validate(); err != nil {
-		return nil, fmt.Errorf("invalid config: %w", err)
-	}
+	stopKeepalive chan struct{}
+	keepaliveDone chan struct{}
+}
 
-	// Configuration des options de connexion
+// buildClickhouseOptions construit les options de connexion clickhouse-go à partir de Config.
+func buildClickhouseOptions(config *Config) (*clickhouse.Options, error) {
 	options := &clickhouse.Options{
 		Addr: []string{fmt.Sprintf("%s:%d", config.Host, config.Port)},
 		Auth: clickhouse.Auth{
@@ -50,124 +49,108 @@ func NewClient(config *Config) (*Client, error) {
 		},
 	}
 
-	// Configuration TLS conditionnelle
 	if config.UseTLS {
-		options.TLS = &tls.Config{
-			InsecureSkipVerify: true, // Pour développement - à changer en production
+		tlsConfig, err := buildTLSConfig(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build TLS config: %w", err)
 		}
+		options.TLS = tlsConfig
+	}
+
+	return options, nil
+}
+
+// connect ouvre une paire (connexion native, connexion SQL) fraîche et vérifie qu'elle
+// répond, sans toucher à l'état du Client. Utilisé à la fois par NewClient et par le
+// keepalive pour reconstruire une connexion restée inactive.
+func connect(config *Config) (driver.Conn, *sql.DB, error) {
+	options, err := buildClickhouseOptions(config)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Connexion SQL pour les requêtes complexes
 	db := clickhouse.OpenDB(options)
 
-	// Test de la connexion SQL
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	if err := db.PingContext(ctx); err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to ping ClickHouse database: %w", err)
+		return nil, nil, fmt.Errorf("failed to ping ClickHouse database: %w", err)
 	}
 
-	// Connexion native pour les requêtes simples
 	conn, err := clickhouse.Open(options)
 	if err != nil {
 		db.Close()
-		return nil, fmt.Errorf("failed to open ClickHouse native connection: %w", err)
+		return nil, nil, fmt.Errorf("failed to open ClickHouse native connection: %w", err)
 	}
 
-	// Test de la connexion native
 	if err := conn.Ping(ctx); err != nil {
 		db.Close()
 		conn.Close()
-		return nil, fmt.Errorf("failed to ping ClickHouse native connection: %w", err)
+		return nil, nil, fmt.Errorf("failed to ping ClickHouse native connection: %w", err)
 	}
 
-	return &Client{
-		conn:   conn,
-		db:     db,
-		config: config,
-	}, nil
+	return conn, db, nil
 }
 
-// ValidateQuery valide que la requête SQL est sécurisée
-func (c *Client) ValidateQuery(query string) error {
-	queryLower := strings.ToLower(strings.TrimSpace(query))
-
-	// Vérifier que c'est bien une requête SELECT
-	if !strings.HasPrefix(queryLower, "select") {
-		return fmt.Errorf("only SELECT queries are allowed")
-	}
-
-	// Blacklist des mots clés dangereux (plus précise)
-	dangerousKeywords := []string{
-		" drop ", " delete ", " insert ", " update ", " alter ",
-		" truncate ", " grant ", " revoke ", " exec ", " execute ",
-		"drop table", "drop database", "create table", "create database",
-		"alter table", "truncate table", "insert into",
+// NewClient crée un nouveau client ClickHouse et démarre son keepalive en arrière-plan
+func NewClient(config *Config) (*Client, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
 	}
 
-	// Ajouter des espaces pour éviter les faux positifs
-	queryWithSpaces := " " + queryLower + " "
-
-	for _, keyword := range dangerousKeywords {
-		if strings.Contains(queryWithSpaces, keyword) {
-			return fmt.Errorf("query contains forbidden keyword: %s", strings.TrimSpace(keyword))
-		}
+	conn, db, err := connect(config)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
-}
-
-// DetectColumns détecte quelles colonnes sont présentes dans la requête SELECT
-func (c *Client) DetectColumns(query string) (map[string]bool, error) {
-	// Cette fonction fait une analyse simple de la requête pour détecter les colonnes
-	queryLower := strings.ToLower(query)
-
-	// Extraire la partie SELECT
-	selectIndex := strings.Index(queryLower, "select")
-	fromIndex := strings.Index(queryLower, "from")
-
-	if selectIndex == -1 || fromIndex == -1 || fromIndex <= selectIndex {
-		return nil, fmt.Errorf("invalid SELECT query format")
+	c := &Client{
+		conn:          conn,
+		db:            db,
+		config:        config,
+		stopKeepalive: make(chan struct{}),
+		keepaliveDone: make(chan struct{}),
 	}
 
-	selectPart := query[selectIndex+6 : fromIndex] // +6 pour "select"
-	selectPart = strings.TrimSpace(selectPart)
-
-	columns := map[string]bool{
-		"id":         false,
-		"name":       false,
-		"created_at": false,
-	}
+	go c.runKeepalive()
 
-	// Si c'est SELECT *, toutes les colonnes sont présentes
-	if strings.Contains(selectPart, "*") {
-		for col := range columns {
-			columns[col] = true
-		}
-		return columns, nil
-	}
+	return c, nil
+}
 
-	// Analyser les colonnes individuelles
-	selectLower := strings.ToLower(selectPart)
-	if strings.Contains(selectLower, "id") {
-		columns["id"] = true
-	}
-	if strings.Contains(selectLower, "name") {
-		columns["name"] = true
-	}
-	if strings.Contains(selectLower, "created_at") {
-		columns["created_at"] = true
-	}
+// getConnections retourne la paire de connexions courante sous verrou de lecture.
+func (c *Client) getConnections() (driver.Conn, *sql.DB) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.conn, c.db
+}
 
-	return columns, nil
+// ValidateQuery valide que la requête SQL est sécurisée en l'analysant réellement
+// via l'AST plutôt qu'en cherchant des sous-chaînes, ce qui évite les faux positifs
+// sur des littéraux (ex: un commentaire contenant le mot "drop") et les faux négatifs
+// sur des constructions que la blacklist textuelle ne couvrait pas (JOIN, sous-requêtes, ...).
+func (c *Client) ValidateQuery(query string) error {
+	_, err := ParseQuery(query, c.config.AllowedTables)
+	return err
 }
 
 // SQLResult représente le résultat brut d'une requête SQL
 type SQLResult struct {
 	Columns []string
 	Rows    []map[string]interface{}
+	Stats   QueryStats
+}
+
+// QueryStats rassemble les statistiques de coût remontées par ClickHouse pendant
+// l'exécution, utilisées côté gRPC pour peupler GetRetailersResponse et côté benchmark
+// pour appliquer un budget de coût par requête (cf. TestQuery.MaxScannedRows et consorts).
+// PeakMemoryUsage nécessiterait une lecture de system.query_log par query_id après coup ;
+// non implémenté pour l'instant, le champ reste à 0.
+type QueryStats struct {
+	ScannedRows     int64
+	ScannedBytes    int64
+	RowsBeforeLimit int64
+	PeakMemoryUsage int64
 }
 
 // ExecuteQuery exécute une requête SQL et retourne les résultats bruts
@@ -185,6 +168,113 @@ func (c *Client) ExecuteQuery(ctx context.Context, sqlQuery string, parameters m
 
 	log.Printf("Executing SQL query: %s", sqlQuery)
 
+	// Propager le span gRPC entrant vers le driver ClickHouse pour que la requête
+	// apparaisse comme un span enfant dans le backend de tracing.
+	ctx = contextWithSpan(ctx)
+
+	// Préparer les paramètres
+	var args []interface{}
+	finalQuery := sqlQuery
+
+	// Remplacer les paramètres nommés par des ? dans l'ordre
+	for i := 1; i <= len(parameters); i++ {
+		paramKey := fmt.Sprintf("%d", i)
+		if paramValue, exists := parameters[paramKey]; exists {
+			args = append(args, paramValue)
+		}
+	}
+
+	// Ajouter la pagination si spécifiée
+	if limit > 0 {
+		finalQuery += fmt.Sprintf(" LIMIT %d", limit)
+	}
+	if offset > 0 {
+		finalQuery += fmt.Sprintf(" OFFSET %d", offset)
+	}
+
+	// Exécuter la requête, avec retry/backoff sur les erreurs transitoires. À chaque
+	// tentative on relit c.db via getConnections() pour profiter d'une éventuelle
+	// reconnexion effectuée entre-temps par le keepalive.
+	var result *SQLResult
+	err := withRetry(ctx, c.config.Retry, func() error {
+		// Une tentative = un callback de progression propre : les stats ne doivent pas
+		// s'accumuler à travers les retries.
+		var stats QueryStats
+		queryCtx := clickhouse.Context(ctx, clickhouse.WithProgress(func(p *clickhouse.Progress) {
+			stats.ScannedRows += int64(p.Rows)
+			stats.ScannedBytes += int64(p.Bytes)
+			stats.RowsBeforeLimit = int64(p.TotalRows)
+		}))
+
+		_, db := c.getConnections()
+
+		rows, err := db.QueryContext(queryCtx, finalQuery, args...)
+		if err != nil {
+			return fmt.Errorf("failed to execute query: %w", err)
+		}
+		defer rows.Close()
+
+		columnNames, err := rows.Columns()
+		if err != nil {
+			return fmt.Errorf("failed to get column names: %w", err)
+		}
+
+		columnValues := make([]interface{}, len(columnNames))
+		columnPointers := make([]interface{}, len(columnNames))
+		for i := range columnValues {
+			columnPointers[i] = &columnValues[i]
+		}
+
+		var resultRows []map[string]interface{}
+
+		for rows.Next() {
+			if err := rows.Scan(columnPointers...); err != nil {
+				return fmt.Errorf("failed to scan row: %w", err)
+			}
+
+			rowMap := make(map[string]interface{})
+			for i, columnName := range columnNames {
+				rowMap[columnName] = columnValues[i]
+			}
+
+			resultRows = append(resultRows, rowMap)
+		}
+
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error iterating rows: %w", err)
+		}
+
+		result = &SQLResult{Columns: columnNames, Rows: resultRows, Stats: stats}
+		return nil
+	})
+
+	if err != nil {
+		queryDuration.WithLabelValues("error").Observe(time.Since(start).Seconds())
+		return nil, 0, err
+	}
+
+	executionTime := time.Since(start).Milliseconds()
+
+	queryDuration.WithLabelValues("success").Observe(time.Since(start).Seconds())
+	rowsReturned.Add(float64(len(result.Rows)))
+
+	log.Printf("Query executed successfully: %d rows returned in %dms", len(result.Rows), executionTime)
+	return result, executionTime, nil
+}
+
+// ExecuteQueryStream exécute une requête SQL et invoque callback pour chaque row au fur et à mesure
+// qu'elle est lue depuis le driver, sans jamais matérialiser le résultat complet en mémoire.
+func (c *Client) ExecuteQueryStream(ctx context.Context, sqlQuery string, parameters map[string]string, limit, offset int32, callback func(row map[string]interface{}) error) error {
+	if sqlQuery == "" {
+		return fmt.Errorf("sql_query cannot be empty")
+	}
+
+	if err := c.ValidateQuery(sqlQuery); err != nil {
+		return fmt.Errorf("invalid query: %w", err)
+	}
+
+	log.Printf("Executing SQL query (stream): %s", sqlQuery)
+
 	// Préparer les paramètres
 	var args []interface{}
 	finalQuery := sqlQuery
@@ -205,70 +295,78 @@ func (c *Client) ExecuteQuery(ctx context.Context, sqlQuery string, parameters m
 		finalQuery += fmt.Sprintf(" OFFSET %d", offset)
 	}
 
-	// Exécuter la requête
-	rows, err := c.db.QueryContext(ctx, finalQuery, args...)
+	_, db := c.getConnections()
+
+	rows, err := db.QueryContext(ctx, finalQuery, args...)
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to execute query: %w", err)
+		return fmt.Errorf("failed to execute query: %w", err)
 	}
 	defer rows.Close()
 
-	// Analyser les colonnes
 	columnNames, err := rows.Columns()
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to get column names: %w", err)
+		return fmt.Errorf("failed to get column names: %w", err)
 	}
 
-	// Préparer le scan
 	columnValues := make([]interface{}, len(columnNames))
 	columnPointers := make([]interface{}, len(columnNames))
 	for i := range columnValues {
 		columnPointers[i] = &columnValues[i]
 	}
 
-	// Scanner toutes les rows
-	var resultRows []map[string]interface{}
+	rowCount := 0
 
 	for rows.Next() {
-		err := rows.Scan(columnPointers...)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan row: %w", err)
+		// Respecter l'annulation du contexte (ex: client déconnecté) entre deux rows
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := rows.Scan(columnPointers...); err != nil {
+			return fmt.Errorf("failed to scan row: %w", err)
 		}
 
-		// Créer une map pour cette row
-		rowMap := make(map[string]interface{})
+		rowMap := make(map[string]interface{}, len(columnNames))
 		for i, columnName := range columnNames {
 			rowMap[columnName] = columnValues[i]
 		}
 
-		resultRows = append(resultRows, rowMap)
-	}
+		if err := callback(rowMap); err != nil {
+			return err
+		}
 
-	if err := rows.Err(); err != nil {
-		return nil, 0, fmt.Errorf("error iterating rows: %w", err)
+		rowCount++
 	}
 
-	executionTime := time.Since(start).Milliseconds()
-
-	result := &SQLResult{
-		Columns: columnNames,
-		Rows:    resultRows,
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating rows: %w", err)
 	}
 
-	log.Printf("Query executed successfully: %d rows returned in %dms", len(resultRows), executionTime)
-	return result, executionTime, nil
+	log.Printf("Query stream completed successfully: %d rows streamed", rowCount)
+	return nil
 }
 
-// TestConnection teste la connexion ClickHouse (pour health check)
+// TestConnection teste la connexion ClickHouse (pour health check), avec retry/backoff
+// sur les erreurs transitoires plutôt que de remonter la première erreur réseau.
 func (c *Client) TestConnection(ctx context.Context) error {
-	testCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
-	defer cancel()
-
-	_, err := c.db.QueryContext(testCtx, "SELECT 1")
-	return err
+	return withRetry(ctx, c.config.Retry, func() error {
+		testCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		_, db := c.getConnections()
+		_, err := db.QueryContext(testCtx, "SELECT 1")
+		return err
+	})
 }
 
-// Close ferme les connexions
+// Close arrête le keepalive et ferme les connexions
 func (c *Client) Close() error {
+	close(c.stopKeepalive)
+	<-c.keepaliveDone
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	var dbErr, connErr error
 
 	if c.db != nil {
@@ -2,6 +2,7 @@ package clickhouse
 
 import (
 	"fmt"
+	"time"
 )
 
 // Config contient la configuration pour ClickHouse
@@ -13,6 +14,35 @@ type Config struct {
 	Password string `envconfig:"CLICKHOUSE_PASSWORD" default:""`
 	UseTLS   bool   `envconfig:"CLICKHOUSE_USE_TLS" default:"false"`
 	Debug    bool   `envconfig:"CLICKHOUSE_DEBUG" default:"false"`
+
+	// AllowedTables restreint les requêtes aux tables listées (séparées par des virgules).
+	// Une liste vide n'applique aucune restriction au-delà du blacklist des tables système.
+	AllowedTables []string `envconfig:"CLICKHOUSE_ALLOWED_TABLES" default:""`
+
+	// Options TLS pour les déploiements en production. TLSInsecureSkipVerify vaut false
+	// par défaut : contrairement au comportement précédent, la vérification du certificat
+	// serveur est active dès que UseTLS est activé.
+	TLSCAFile             string `envconfig:"CLICKHOUSE_TLS_CA_FILE" default:""`
+	TLSCertFile           string `envconfig:"CLICKHOUSE_TLS_CERT_FILE" default:""`
+	TLSKeyFile            string `envconfig:"CLICKHOUSE_TLS_KEY_FILE" default:""`
+	TLSServerName         string `envconfig:"CLICKHOUSE_TLS_SERVER_NAME" default:""`
+	TLSInsecureSkipVerify bool   `envconfig:"CLICKHOUSE_TLS_INSECURE_SKIP_VERIFY" default:"false"`
+
+	// IdlePingInterval contrôle la fréquence du keepalive qui détecte et reconstruit
+	// une connexion restée inactive trop longtemps.
+	IdlePingInterval time.Duration `envconfig:"CLICKHOUSE_IDLE_PING_INTERVAL" default:"30s"`
+
+	Retry RetryConfig
+}
+
+// RetryConfig paramètre le backoff exponentiel appliqué aux erreurs transitoires,
+// suivant le schéma de gRPC connection-backoff : delay = min(base*factor^attempt, max) ± jitter.
+type RetryConfig struct {
+	BaseDelay   time.Duration `envconfig:"CLICKHOUSE_RETRY_BASE_DELAY" default:"1s"`
+	Factor      float64       `envconfig:"CLICKHOUSE_RETRY_FACTOR" default:"1.6"`
+	Jitter      float64       `envconfig:"CLICKHOUSE_RETRY_JITTER" default:"0.2"`
+	MaxDelay    time.Duration `envconfig:"CLICKHOUSE_RETRY_MAX_DELAY" default:"30s"`
+	MaxAttempts int           `envconfig:"CLICKHOUSE_RETRY_MAX_ATTEMPTS" default:"5"`
 }
 
 // Validate valide la configuration
@@ -33,6 +63,16 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("username cannot be empty")
 	}
 
+	hasCert := c.TLSCertFile != ""
+	hasKey := c.TLSKeyFile != ""
+	if hasCert != hasKey {
+		return fmt.Errorf("TLSCertFile and TLSKeyFile must be set together")
+	}
+
+	if !c.UseTLS && (c.TLSCAFile != "" || hasCert) {
+		return fmt.Errorf("TLS client certificates/CA are set but UseTLS is false")
+	}
+
 	return nil
 }
 
@@ -0,0 +1,21 @@
+package clickhouse
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Métriques Prometheus exposées par le client ClickHouse. Elles sont enregistrées
+// une seule fois dans le registre par défaut, quel que soit le nombre de Client créés.
+var (
+	queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "clickhouse_query_duration_seconds",
+		Help:    "Durée d'exécution des requêtes ClickHouse, par statut",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"status"})
+
+	rowsReturned = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "clickhouse_rows_returned",
+		Help: "Nombre total de rows retournées par les requêtes ClickHouse",
+	})
+)
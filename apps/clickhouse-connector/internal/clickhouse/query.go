@@ -0,0 +1,186 @@
+package clickhouse
+
+import (
+	"fmt"
+	"strings"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+// systemTableBlacklist liste les schémas ClickHouse qui ne doivent jamais être
+// exposés au travers du connecteur, même si l'appelant les whiteliste par erreur.
+var systemTableBlacklist = map[string]bool{
+	"system":             true,
+	"information_schema": true,
+}
+
+// ParsedQuery est le résultat de l'analyse AST d'une requête SELECT : la liste
+// des tables référencées et la projection (avec alias résolus) dans l'ordre
+// d'apparition, prêtes à être mappées vers les champs protobuf.
+type ParsedQuery struct {
+	Tables  []string
+	Columns []ParsedColumn
+}
+
+// ParsedColumn représente une colonne de la clause SELECT après résolution d'alias.
+type ParsedColumn struct {
+	// Name est le nom sous lequel la colonne apparaît dans le résultat (alias si présent).
+	Name string
+	// Expr est l'expression SQL d'origine (nom de colonne, fonction, littéral, ...).
+	Expr string
+	// Star est vrai pour un "*" ou "table.*", auquel cas Name/Expr ne sont pas significatifs.
+	Star bool
+}
+
+// ParseQuery analyse la requête et rejette tout ce qui n'est pas un SELECT (ou
+// WITH ... SELECT) simple : pas de sous-requêtes, pas de JOIN, pas de table hors
+// de allowedTables (si la liste n'est pas vide), pas de schéma système.
+func ParseQuery(query string, allowedTables []string) (*ParsedQuery, error) {
+	stmt, err := sqlparser.Parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse query: %w", err)
+	}
+
+	var selectStmt *sqlparser.Select
+
+	switch s := stmt.(type) {
+	case *sqlparser.Select:
+		selectStmt = s
+	case *sqlparser.Union:
+		return nil, fmt.Errorf("UNION queries are not allowed")
+	default:
+		return nil, fmt.Errorf("only SELECT queries are allowed, got %T", stmt)
+	}
+
+	if selectStmt.With != nil {
+		// On autorise "WITH ... SELECT" mais chaque CTE doit lui-même être un SELECT simple,
+		// et ses propres tables passent par le même contrôle whitelist/blacklist que la requête
+		// principale : sinon "WITH leak AS (SELECT * FROM system.users) SELECT * FROM leak"
+		// contournerait la blacklist en la déplaçant dans le CTE.
+		for _, cte := range selectStmt.With.Ctes {
+			cteSelect, ok := cte.Subquery.Select.(*sqlparser.Select)
+			if !ok {
+				return nil, fmt.Errorf("CTE %q must be a simple SELECT", cte.ID.String())
+			}
+			if _, err := validateSelect(cteSelect, allowedTables); err != nil {
+				return nil, fmt.Errorf("CTE %q: %w", cte.ID.String(), err)
+			}
+		}
+	}
+
+	tables, err := validateSelect(selectStmt, allowedTables)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := extractColumns(selectStmt.SelectExprs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ParsedQuery{Tables: tables, Columns: columns}, nil
+}
+
+// validateSelect rejette toute sous-requête portée par selectStmt (WHERE, HAVING, ON, ...) via
+// HasSubquery, puis délègue à extractTables la validation de la clause FROM contre la whitelist
+// et la blacklist système. Utilisé à la fois pour la requête principale et pour chaque CTE.
+func validateSelect(selectStmt *sqlparser.Select, allowedTables []string) ([]string, error) {
+	if HasSubquery(selectStmt) {
+		return nil, fmt.Errorf("subqueries are not allowed")
+	}
+
+	return extractTables(selectStmt.From, allowedTables)
+}
+
+// extractTables parcourt la clause FROM, rejette les JOIN et les sous-requêtes,
+// et vérifie chaque table contre la whitelist (si fournie) et la blacklist système.
+func extractTables(from sqlparser.TableExprs, allowedTables []string) ([]string, error) {
+	var tables []string
+
+	var allowed map[string]bool
+	if len(allowedTables) > 0 {
+		allowed = make(map[string]bool, len(allowedTables))
+		for _, t := range allowedTables {
+			allowed[strings.ToLower(t)] = true
+		}
+	}
+
+	for _, expr := range from {
+		switch t := expr.(type) {
+		case *sqlparser.AliasedTableExpr:
+			tableName, ok := t.Expr.(sqlparser.TableName)
+			if !ok {
+				return nil, fmt.Errorf("subqueries in FROM are not allowed")
+			}
+
+			name := tableName.Name.String()
+			schema := tableName.Qualifier.String()
+
+			if systemTableBlacklist[strings.ToLower(schema)] || systemTableBlacklist[strings.ToLower(name)] {
+				return nil, fmt.Errorf("access to system table %q is not allowed", name)
+			}
+
+			if allowed != nil && !allowed[strings.ToLower(name)] {
+				return nil, fmt.Errorf("table %q is not in the allowed tables list", name)
+			}
+
+			tables = append(tables, name)
+		case *sqlparser.JoinTableExpr:
+			return nil, fmt.Errorf("JOIN is not allowed")
+		default:
+			return nil, fmt.Errorf("unsupported FROM expression: %T", expr)
+		}
+	}
+
+	return tables, nil
+}
+
+// extractColumns résout la liste de projection, y compris les alias (AS) et les
+// expressions qualifiées ("t.col"), dans l'ordre d'apparition.
+func extractColumns(exprs sqlparser.SelectExprs) ([]ParsedColumn, error) {
+	var columns []ParsedColumn
+
+	for _, expr := range exprs {
+		switch e := expr.(type) {
+		case *sqlparser.StarExpr:
+			columns = append(columns, ParsedColumn{Star: true})
+		case *sqlparser.AliasedExpr:
+			name := e.As.String()
+			if name == "" {
+				if col, ok := e.Expr.(*sqlparser.ColName); ok {
+					name = col.Name.String()
+				} else {
+					name = sqlparser.String(e.Expr)
+				}
+			}
+
+			columns = append(columns, ParsedColumn{
+				Name: name,
+				Expr: sqlparser.String(e.Expr),
+			})
+		default:
+			return nil, fmt.Errorf("unsupported select expression: %T", expr)
+		}
+	}
+
+	return columns, nil
+}
+
+// HasSubquery signale si une requête contient une sous-requête où que ce soit dans son arbre
+// (WHERE, HAVING, ON, ...), ce qui est interdit par défaut. Appelé par validateSelect.
+// La clause WITH n'est pas parcourue : les corps des CTE sont eux-mêmes des sous-requêtes
+// et sont validés un par un par ParseQuery.
+func HasSubquery(selectStmt *sqlparser.Select) bool {
+	found := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if _, ok := node.(*sqlparser.With); ok {
+			return false, nil
+		}
+		if _, ok := node.(*sqlparser.Subquery); ok {
+			found = true
+			return false, nil
+		}
+		return true, nil
+	}, selectStmt)
+	return found
+}
@@ -0,0 +1,161 @@
+package clickhouse
+
+import (
+	"strings"
+	"testing"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+func TestParseQuery_Allowed(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		allowedTables []string
+	}{
+		{
+			name:  "simple select",
+			query: "SELECT id, name FROM retailers",
+		},
+		{
+			name:  "select with alias",
+			query: "SELECT id AS retailer_id, name FROM retailers",
+		},
+		{
+			name:  "star",
+			query: "SELECT * FROM retailers",
+		},
+		{
+			name:          "table in whitelist",
+			query:         "SELECT id FROM retailers",
+			allowedTables: []string{"retailers"},
+		},
+		{
+			name:  "cte with clean table",
+			query: "WITH recent AS (SELECT id, name FROM retailers) SELECT * FROM recent",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseQuery(tt.query, tt.allowedTables); err != nil {
+				t.Fatalf("ParseQuery(%q) returned unexpected error: %v", tt.query, err)
+			}
+		})
+	}
+}
+
+func TestParseQuery_Rejected(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		allowedTables []string
+		wantErrSubstr string
+	}{
+		{
+			name:          "system schema in FROM",
+			query:         "SELECT * FROM system.users",
+			wantErrSubstr: "system table",
+		},
+		{
+			name:          "information_schema in FROM",
+			query:         "SELECT * FROM information_schema.tables",
+			wantErrSubstr: "system table",
+		},
+		{
+			name:          "subquery in WHERE leaks system table",
+			query:         "SELECT name FROM retailers WHERE id IN (SELECT id FROM system.users)",
+			wantErrSubstr: "subquer",
+		},
+		{
+			name:          "subquery in HAVING",
+			query:         "SELECT id, COUNT(*) FROM retailers GROUP BY id HAVING COUNT(*) > (SELECT 1 FROM system.users)",
+			wantErrSubstr: "subquer",
+		},
+		{
+			name:          "cte leaks a system table",
+			query:         "WITH leak AS (SELECT name, password FROM system.users) SELECT * FROM leak",
+			wantErrSubstr: "system table",
+		},
+		{
+			name:          "cte not in whitelist",
+			query:         "WITH recent AS (SELECT id FROM other_table) SELECT * FROM recent",
+			allowedTables: []string{"retailers"},
+			wantErrSubstr: "not in the allowed tables list",
+		},
+		{
+			name:          "join is rejected",
+			query:         "SELECT r.id FROM retailers r JOIN orders o ON r.id = o.retailer_id",
+			wantErrSubstr: "JOIN",
+		},
+		{
+			name:          "table not in whitelist",
+			query:         "SELECT id FROM other_table",
+			allowedTables: []string{"retailers"},
+			wantErrSubstr: "not in the allowed tables list",
+		},
+		{
+			name:          "union is rejected",
+			query:         "SELECT id FROM retailers UNION SELECT id FROM retailers",
+			wantErrSubstr: "UNION",
+		},
+		{
+			name:          "subquery in FROM is rejected",
+			query:         "SELECT id FROM (SELECT id FROM retailers) AS sub",
+			wantErrSubstr: "subquer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseQuery(tt.query, tt.allowedTables)
+			if err == nil {
+				t.Fatalf("ParseQuery(%q) succeeded, want error containing %q", tt.query, tt.wantErrSubstr)
+			}
+			if !strings.Contains(strings.ToLower(err.Error()), strings.ToLower(tt.wantErrSubstr)) {
+				t.Fatalf("ParseQuery(%q) error = %q, want substring %q", tt.query, err.Error(), tt.wantErrSubstr)
+			}
+		})
+	}
+}
+
+func TestHasSubquery(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{
+			name:  "no subquery",
+			query: "SELECT id FROM retailers WHERE id = 1",
+			want:  false,
+		},
+		{
+			name:  "subquery in WHERE",
+			query: "SELECT id FROM retailers WHERE id IN (SELECT id FROM other_table)",
+			want:  true,
+		},
+		{
+			name:  "subquery in HAVING",
+			query: "SELECT id, COUNT(*) FROM retailers GROUP BY id HAVING COUNT(*) > (SELECT 1 FROM other_table)",
+			want:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmt, err := sqlparser.Parse(tt.query)
+			if err != nil {
+				t.Fatalf("failed to parse query: %v", err)
+			}
+			selectStmt, ok := stmt.(*sqlparser.Select)
+			if !ok {
+				t.Fatalf("expected *sqlparser.Select, got %T", stmt)
+			}
+
+			if got := HasSubquery(selectStmt); got != tt.want {
+				t.Fatalf("HasSubquery(%q) = %v, want %v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
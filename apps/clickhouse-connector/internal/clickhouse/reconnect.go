@@ -0,0 +1,65 @@
+package clickhouse
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// runKeepalive ping périodiquement la connexion native et, en cas d'échec, reconstruit
+// conn/db sous verrou afin que les appelants en cours voient une connexion fraîche dès
+// leur prochaine tentative plutôt que de rester bloqués sur une connexion morte.
+func (c *Client) runKeepalive() {
+	defer close(c.keepaliveDone)
+
+	interval := c.config.IdlePingInterval
+	if interval <= 0 {
+		interval = 30 * time.Second
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopKeepalive:
+			return
+		case <-ticker.C:
+			c.pingAndReconnect()
+		}
+	}
+}
+
+// pingAndReconnect teste la connexion native et la remplace si le ping échoue.
+func (c *Client) pingAndReconnect() {
+	conn, _ := c.getConnections()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := conn.Ping(ctx); err == nil {
+		return
+	}
+
+	log.Printf("ClickHouse keepalive ping failed, reconnecting...")
+
+	newConn, newDB, err := connect(c.config)
+	if err != nil {
+		log.Printf("ClickHouse reconnect failed, keeping existing connection: %v", err)
+		return
+	}
+
+	c.mu.Lock()
+	oldConn, oldDB := c.conn, c.db
+	c.conn, c.db = newConn, newDB
+	c.mu.Unlock()
+
+	if oldDB != nil {
+		oldDB.Close()
+	}
+	if oldConn != nil {
+		oldConn.Close()
+	}
+
+	log.Println("ClickHouse connection successfully rebuilt")
+}
@@ -0,0 +1,106 @@
+package clickhouse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+)
+
+// backoffDelay calcule le délai avant la tentative suivante en suivant le schéma de
+// gRPC connection-backoff : delay = min(base*factor^attempt, max), puis applique un
+// jitter multiplicatif ±jitter pour éviter les retries synchronisés entre clients.
+func backoffDelay(retry RetryConfig, attempt int) time.Duration {
+	delay := float64(retry.BaseDelay) * math.Pow(retry.Factor, float64(attempt))
+	if maxDelay := float64(retry.MaxDelay); delay > maxDelay {
+		delay = maxDelay
+	}
+
+	if retry.Jitter > 0 {
+		delta := retry.Jitter * delay
+		delay = delay - delta + rand.Float64()*2*delta
+	}
+
+	return time.Duration(delay)
+}
+
+// isTransientError classe les erreurs driver ClickHouse susceptibles de disparaître
+// après une nouvelle tentative : blips réseau, code ClickHouse 210 (NETWORK_ERROR) et
+// connexions mortes côté pool SQL standard.
+func isTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var chErr *clickhouse.Exception
+	if errors.As(err, &chErr) {
+		// Code 210 = NETWORK_ERROR côté ClickHouse
+		return chErr.Code == 210
+	}
+
+	msg := strings.ToLower(err.Error())
+	transientSubstrings := []string{
+		"bad connection",
+		"connection reset",
+		"broken pipe",
+		"connection refused",
+		"i/o timeout",
+		"eof",
+	}
+	for _, s := range transientSubstrings {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// withRetry exécute op en réessayant selon RetryConfig tant que l'erreur est classée
+// transitoire, en respectant l'annulation du contexte entre deux tentatives.
+func withRetry(ctx context.Context, retry RetryConfig, op func() error) error {
+	var lastErr error
+
+	for attempt := 0; attempt < retry.MaxAttempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		lastErr = op()
+		if lastErr == nil {
+			return nil
+		}
+
+		if !isTransientError(lastErr) {
+			return lastErr
+		}
+
+		if attempt == retry.MaxAttempts-1 {
+			break
+		}
+
+		delay := backoffDelay(retry, attempt)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+
+	return lastErr
+}
@@ -0,0 +1,82 @@
+package clickhouse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestBackoffDelay(t *testing.T) {
+	retry := RetryConfig{
+		BaseDelay: 1 * time.Second,
+		Factor:    2,
+		MaxDelay:  10 * time.Second,
+		Jitter:    0,
+	}
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 0, want: 1 * time.Second},
+		{attempt: 1, want: 2 * time.Second},
+		{attempt: 2, want: 4 * time.Second},
+		{attempt: 3, want: 8 * time.Second},
+		{attempt: 4, want: 10 * time.Second}, // capped by MaxDelay
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("attempt=%d", tt.attempt), func(t *testing.T) {
+			if got := backoffDelay(retry, tt.attempt); got != tt.want {
+				t.Fatalf("backoffDelay(attempt=%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffDelay_JitterStaysInRange(t *testing.T) {
+	retry := RetryConfig{
+		BaseDelay: 1 * time.Second,
+		Factor:    1,
+		MaxDelay:  10 * time.Second,
+		Jitter:    0.2,
+	}
+
+	base := float64(retry.BaseDelay)
+	minDelay := time.Duration(base * 0.8)
+	maxDelay := time.Duration(base * 1.2)
+
+	for i := 0; i < 100; i++ {
+		delay := backoffDelay(retry, 0)
+		if delay < minDelay || delay > maxDelay {
+			t.Fatalf("backoffDelay() = %v, want within [%v, %v]", delay, minDelay, maxDelay)
+		}
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil error", err: nil, want: false},
+		{name: "io.EOF", err: io.EOF, want: true},
+		{name: "wrapped io.EOF", err: fmt.Errorf("query failed: %w", io.EOF), want: true},
+		{name: "connection reset substring", err: errors.New("read: connection reset by peer"), want: true},
+		{name: "broken pipe substring", err: errors.New("write: broken pipe"), want: true},
+		{name: "i/o timeout substring", err: errors.New("dial tcp: i/o timeout"), want: true},
+		{name: "syntax error is not transient", err: errors.New("syntax error near SELECT"), want: false},
+		{name: "permission denied is not transient", err: errors.New("permission denied"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientError(tt.err); got != tt.want {
+				t.Fatalf("isTransientError(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package clickhouse
+
+import (
+	"context"
+
+	chlib "github.com/ClickHouse/clickhouse-go/v2"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// contextWithSpan attache le SpanContext OpenTelemetry actif (propagé depuis
+// l'interceptor gRPC otelgrpc) au contexte passé au driver ClickHouse, afin que
+// les spans de requête apparaissent comme enfants du span gRPC dans le tracing.
+func contextWithSpan(ctx context.Context) context.Context {
+	span := trace.SpanFromContext(ctx)
+	if !span.SpanContext().IsValid() {
+		return ctx
+	}
+
+	return chlib.Context(ctx, chlib.WithSpan(span.SpanContext()))
+}
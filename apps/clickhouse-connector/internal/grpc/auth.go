@@ -0,0 +1,146 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/MicahParks/keyfunc/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Principal représente l'identité extraite d'un JWT validé, disponible dans le
+// contexte des handlers pour le logging et la réécriture de requête par tenant.
+type Principal struct {
+	Subject string
+	Tenant  string
+	Claims  jwt.MapClaims
+}
+
+type principalKey struct{}
+
+// PrincipalFromContext récupère le Principal attaché par l'AuthInterceptor, s'il existe.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok
+}
+
+// AuthInterceptor valide les JWT bearer portés par le metadata "authorization" contre
+// une JWKS, avec cache et rotation automatique des clés gérés par keyfunc.
+type AuthInterceptor struct {
+	keyfunc  *keyfunc.JWKS
+	issuer   string
+	audience string
+}
+
+// NewAuthInterceptor construit un AuthInterceptor en récupérant et mettant en cache la
+// JWKS exposée par jwksURL ; keyfunc rafraîchit les clés automatiquement en arrière-plan.
+func NewAuthInterceptor(jwksURL, issuer, audience string) (*AuthInterceptor, error) {
+	kf, err := keyfunc.Get(jwksURL, keyfunc.Options{
+		RefreshInterval:   time.Hour,
+		RefreshUnknownKID: true,
+		RefreshErrorHandler: func(err error) {
+			// keyfunc conserve les clés précédemment chargées en cas d'échec du refresh
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS from %s: %w", jwksURL, err)
+	}
+
+	return &AuthInterceptor{keyfunc: kf, issuer: issuer, audience: audience}, nil
+}
+
+// Unary authentifie un appel unaire et injecte le Principal dans le contexte du handler.
+func (a *AuthInterceptor) Unary(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	principal, err := a.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler(context.WithValue(ctx, principalKey{}, principal), req)
+}
+
+// Stream authentifie un appel streaming et injecte le Principal dans le contexte du stream.
+func (a *AuthInterceptor) Stream(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	principal, err := a.authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+
+	return handler(srv, &authenticatedServerStream{
+		ServerStream: stream,
+		ctx:          context.WithValue(stream.Context(), principalKey{}, principal),
+	})
+}
+
+func (a *AuthInterceptor) authenticate(ctx context.Context) (*Principal, error) {
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed, err := jwt.Parse(token, a.keyfunc.Keyfunc,
+		jwt.WithIssuer(a.issuer),
+		jwt.WithAudience(a.audience),
+		jwt.WithValidMethods([]string{"RS256", "ES256"}),
+	)
+	if err != nil || !parsed.Valid {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid token: %v", err)
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid token claims")
+	}
+
+	subject, _ := claims.GetSubject()
+
+	tenant, _ := claims["tenant"].(string)
+
+	return &Principal{Subject: subject, Tenant: tenant, Claims: claims}, nil
+}
+
+// bearerToken extrait le token du metadata gRPC "authorization: Bearer <token>".
+func bearerToken(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", status.Error(codes.Unauthenticated, "missing authorization metadata")
+	}
+
+	const prefix = "Bearer "
+	if !strings.HasPrefix(values[0], prefix) {
+		return "", status.Error(codes.Unauthenticated, "authorization header must use the Bearer scheme")
+	}
+
+	return strings.TrimPrefix(values[0], prefix), nil
+}
+
+// authenticatedServerStream injecte un contexte enrichi du Principal dans un ServerStream.
+type authenticatedServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -1,5 +1,7 @@
 package grpc
 
+import "fmt"
+
 // ServerConfig contient la configuration du serveur gRPC
 type ServerConfig struct {
 	Port              int    `envconfig:"GRPC_PORT" default:"50051"`
@@ -8,4 +10,58 @@ type ServerConfig struct {
 	MaxSendMsgSize    int    `envconfig:"GRPC_MAX_SEND_MSG_SIZE" default:"104857600"` // 100MB
 	EnableReflection  bool   `envconfig:"GRPC_ENABLE_REFLECTION" default:"true"`
 	EnableHealthCheck bool   `envconfig:"GRPC_ENABLE_HEALTH_CHECK" default:"true"`
+
+	// EnableMetrics expose un endpoint HTTP /metrics au format Prometheus sur MetricsPort.
+	EnableMetrics bool `envconfig:"ENABLE_METRICS" default:"true"`
+	MetricsPort   int  `envconfig:"METRICS_PORT" default:"9090"`
+
+	// EnableTracing active l'export des traces OpenTelemetry vers OTLPEndpoint.
+	EnableTracing bool   `envconfig:"ENABLE_TRACING" default:"false"`
+	OTLPEndpoint  string `envconfig:"OTLP_ENDPOINT" default:"localhost:4317"`
+
+	// TLSCertFile/TLSKeyFile activent TLS sur le serveur gRPC. ClientCAFile, si renseigné,
+	// active en plus le mTLS en exigeant et vérifiant un certificat client.
+	TLSCertFile  string `envconfig:"GRPC_TLS_CERT_FILE" default:""`
+	TLSKeyFile   string `envconfig:"GRPC_TLS_KEY_FILE" default:""`
+	ClientCAFile string `envconfig:"GRPC_CLIENT_CA_FILE" default:""`
+
+	// EnableAuth active la validation des JWT bearer présents dans le metadata "authorization"
+	// contre la JWKS exposée par JWKSURL.
+	EnableAuth  bool   `envconfig:"GRPC_ENABLE_AUTH" default:"false"`
+	JWKSURL     string `envconfig:"GRPC_JWKS_URL" default:""`
+	JWTIssuer   string `envconfig:"GRPC_JWT_ISSUER" default:""`
+	JWTAudience string `envconfig:"GRPC_JWT_AUDIENCE" default:""`
+
+	// Reattach fait écouter le serveur sur un port loopback aléatoire et imprime ses
+	// coordonnées de connexion sur stdout au lieu de se binder sur Host:Port (cf. pkg/reattach).
+	// Pensé pour le développement local : lancer le binaire sous dlv ou depuis un harness de
+	// test et attacher un client au processus déjà démarré plutôt que d'en spawn un nouveau.
+	Reattach bool `envconfig:"CLICKHOUSE_CONNECTOR_REATTACH" default:"false"`
+}
+
+// Validate refuse un démarrage dont la configuration TLS/auth est incomplète plutôt que
+// de démarrer silencieusement en clair ou sans vérification.
+func (c *ServerConfig) Validate() error {
+	hasCert := c.TLSCertFile != ""
+	hasKey := c.TLSKeyFile != ""
+
+	if hasCert != hasKey {
+		return fmt.Errorf("TLSCertFile and TLSKeyFile must be set together")
+	}
+
+	if c.ClientCAFile != "" && !hasCert {
+		return fmt.Errorf("ClientCAFile requires TLSCertFile/TLSKeyFile to be set (mTLS needs server TLS)")
+	}
+
+	if c.EnableAuth && c.JWKSURL == "" {
+		return fmt.Errorf("GRPC_JWKS_URL is required when GRPC_ENABLE_AUTH is true")
+	}
+	if c.EnableAuth && c.JWTIssuer == "" {
+		return fmt.Errorf("GRPC_JWT_ISSUER is required when GRPC_ENABLE_AUTH is true")
+	}
+	if c.EnableAuth && c.JWTAudience == "" {
+		return fmt.Errorf("GRPC_JWT_AUDIENCE is required when GRPC_ENABLE_AUTH is true")
+	}
+
+	return nil
 }
@@ -2,29 +2,36 @@ package grpc
 
 import (
 	"context"
+	"fmt"
 	"log"
-	"strings"
 	"time"
 
 	"api-retailers-nest/apps/clickhouse-connector/internal/clickhouse"
+	"api-retailers-nest/apps/clickhouse-connector/internal/schema"
 	pb "api-retailers-nest/packages/proto/go"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// retailersEntity est le nom de l'EntitySchema mappé sur pb.Retailer. Ajouter une colonne
+// à pb.Retailer se fait en ajoutant une entrée à ce schéma YAML, sans toucher au handler ;
+// le handler ne sert que cette entité.
+const retailersEntity = "retailers"
+
 // Handler implémente le service gRPC ClickHouse
 type Handler struct {
 	pb.UnimplementedClickHouseServiceServer
 	client    *clickhouse.Client
+	schemas   *schema.Registry
 	startTime time.Time
 }
 
 // NewHandler crée un nouveau handler gRPC
-func NewHandler(client *clickhouse.Client) *Handler {
+func NewHandler(client *clickhouse.Client, schemas *schema.Registry) *Handler {
 	return &Handler{
 		client:    client,
+		schemas:   schemas,
 		startTime: time.Now(),
 	}
 }
@@ -86,7 +93,13 @@ func (h *Handler) GetRetailers(ctx context.Context, req *pb.GetRetailersRequest)
 	}
 
 	// Convertir les résultats SQL en Retailers
-	retailers := h.convertToRetailers(result)
+	retailers, err := h.convertToRetailers(result)
+	if err != nil {
+		log.Printf("Failed to map query result: %v", err)
+		return &pb.GetRetailersResponse{
+			Error: err.Error(),
+		}, nil
+	}
 
 	// S'assurer que retailers n'est jamais nil (pour forcer l'affichage en JSON)
 	if retailers == nil {
@@ -98,78 +111,153 @@ func (h *Handler) GetRetailers(ctx context.Context, req *pb.GetRetailersRequest)
 		Retailers:       retailers,
 		Count:           int64(len(retailers)),
 		ExecutionTimeMs: executionTime,
+		ScannedRows:     result.Stats.ScannedRows,
+		ScannedBytes:    result.Stats.ScannedBytes,
+		PeakMemoryUsage: result.Stats.PeakMemoryUsage,
+		RowsBeforeLimit: result.Stats.RowsBeforeLimit,
 	}, nil
 }
 
 // convertToRetailers convertit les résultats SQL bruts en types Retailer protobuf
-func (h *Handler) convertToRetailers(result *clickhouse.SQLResult) []*pb.Retailer {
+// en s'appuyant sur l'EntitySchema "retailers" plutôt que sur des noms de colonnes
+// codés en dur, afin que l'ajout d'une colonne ne nécessite qu'une entrée YAML.
+func (h *Handler) convertToRetailers(result *clickhouse.SQLResult) ([]*pb.Retailer, error) {
 	var retailers []*pb.Retailer
 
 	for _, row := range result.Rows {
-		retailer := &pb.Retailer{}
-
-		// Mapper les colonnes aux champs protobuf
-		for columnName, value := range row {
-			if value == nil {
-				continue
-			}
-
-			columnNameLower := strings.ToLower(columnName)
-
-			switch columnNameLower {
-			case "id":
-				if strValue, ok := value.(string); ok {
-					retailer.Id = &strValue
-				}
-			case "name":
-				if strValue, ok := value.(string); ok {
-					retailer.Name = &strValue
-				}
-			case "created_at":
-				if timeValue, ok := value.(time.Time); ok {
-					retailer.CreatedAt = timestamppb.New(timeValue)
-				}
-			}
+		retailer, err := h.mapRowToRetailer(row)
+		if err != nil {
+			return nil, err
 		}
-
 		retailers = append(retailers, retailer)
 	}
 
-	return retailers
+	return retailers, nil
 }
 
-// GetRetailersStream exécute une requête SQL en streaming
-func (h *Handler) GetRetailersStream(req *pb.GetRetailersRequest, stream pb.ClickHouseService_GetRetailersStreamServer) error {
-	// Pour le streaming, on utilise la méthode non-streaming et on streame les résultats
-	resp, err := h.GetRetailers(stream.Context(), req)
-	if err != nil {
-		return err
+// mapRowToRetailer mappe une seule row ClickHouse en type Retailer protobuf via le registre de schémas.
+func (h *Handler) mapRowToRetailer(row map[string]interface{}) (*pb.Retailer, error) {
+	entitySchema, ok := h.schemas.Get(retailersEntity)
+	if !ok {
+		return nil, fmt.Errorf("no schema registered for entity %q", retailersEntity)
 	}
 
-	if resp.Error != "" {
-		return status.Errorf(codes.Internal, "%s", resp.Error)
+	retailer := &pb.Retailer{}
+	if err := schema.MapRow(retailer, entitySchema, row); err != nil {
+		return nil, err
 	}
 
-	// Envoyer chaque retailer individuellement
-	for _, retailer := range resp.Retailers {
-		response := &pb.RetailerStreamResponse{
+	return retailer, nil
+}
+
+// GetRetailersStream exécute une requête SQL et streame les résultats row par row depuis le driver
+// ClickHouse, sans jamais charger le résultat complet en mémoire côté serveur.
+func (h *Handler) GetRetailersStream(req *pb.GetRetailersRequest, stream pb.ClickHouseService_GetRetailersStreamServer) error {
+	ctx := stream.Context()
+	start := time.Now()
+
+	if req.SqlQuery == "" {
+		return status.Error(codes.InvalidArgument, "sql_query cannot be empty")
+	}
+	if req.Limit < 0 {
+		return status.Error(codes.InvalidArgument, "limit cannot be negative")
+	}
+	if req.Offset < 0 {
+		return status.Error(codes.InvalidArgument, "offset cannot be negative")
+	}
+
+	log.Printf("Executing SQL query stream: %s (limit=%d, offset=%d)",
+		truncateString(req.SqlQuery, 200), req.Limit, req.Offset)
+
+	rowCount := 0
+	err := h.client.ExecuteQueryStream(ctx, req.SqlQuery, req.Parameters, req.Limit, req.Offset, func(row map[string]interface{}) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		retailer, err := h.mapRowToRetailer(row)
+		if err != nil {
+			return err
+		}
+
+		// stream.Send bloque tant que le client n'a pas consommé le message précédent,
+		// ce qui fournit la backpressure entre le scan des rows et l'envoi réseau.
+		if err := stream.Send(&pb.RetailerStreamResponse{
 			Retailer: retailer,
 			IsLast:   false,
+		}); err != nil {
+			return err
 		}
 
-		if err := stream.Send(response); err != nil {
-			return err
+		rowCount++
+		return nil
+	})
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
 		}
+		log.Printf("Failed to stream query: %v", err)
+		return status.Errorf(codes.Internal, "%s", err.Error())
 	}
 
-	// Envoyer le marqueur de fin avec le temps d'exécution
-	endResponse := &pb.RetailerStreamResponse{
+	// IsLast n'est émis que sur un EOF propre, une fois toutes les rows envoyées avec succès
+	executionTime := time.Since(start).Milliseconds()
+	log.Printf("Retailers stream completed successfully: %d rows in %dms", rowCount, executionTime)
+	return stream.Send(&pb.RetailerStreamResponse{
 		IsLast:          true,
-		ExecutionTimeMs: resp.ExecutionTimeMs,
+		ExecutionTimeMs: executionTime,
+	})
+}
+
+// StreamRetailers exécute une requête SQL et streame chaque row sous la forme d'un
+// RetailerRow nu, sans l'enveloppe IsLast/ExecutionTimeMs de GetRetailersStream. Destiné aux
+// clients qui veulent le flux de rows le plus léger possible (cf. benchmark.go, TestQuery.Streaming).
+func (h *Handler) StreamRetailers(req *pb.GetRetailersRequest, stream pb.ClickHouseService_StreamRetailersServer) error {
+	ctx := stream.Context()
+
+	if req.SqlQuery == "" {
+		return status.Error(codes.InvalidArgument, "sql_query cannot be empty")
+	}
+	if req.Limit < 0 {
+		return status.Error(codes.InvalidArgument, "limit cannot be negative")
+	}
+	if req.Offset < 0 {
+		return status.Error(codes.InvalidArgument, "offset cannot be negative")
+	}
+
+	log.Printf("Executing SQL query stream (rows): %s (limit=%d, offset=%d)",
+		truncateString(req.SqlQuery, 200), req.Limit, req.Offset)
+
+	rowCount := 0
+	err := h.client.ExecuteQueryStream(ctx, req.SqlQuery, req.Parameters, req.Limit, req.Offset, func(row map[string]interface{}) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		retailer, err := h.mapRowToRetailer(row)
+		if err != nil {
+			return err
+		}
+
+		if err := stream.Send(&pb.RetailerRow{Retailer: retailer}); err != nil {
+			return err
+		}
+
+		rowCount++
+		return nil
+	})
+
+	if err != nil {
+		if ctx.Err() != nil {
+			return status.FromContextError(ctx.Err()).Err()
+		}
+		log.Printf("Failed to stream retailer rows: %v", err)
+		return status.Errorf(codes.Internal, "%s", err.Error())
 	}
 
-	log.Printf("Retailers stream completed successfully in %dms", resp.ExecutionTimeMs)
-	return stream.Send(endResponse)
+	log.Printf("StreamRetailers completed successfully: %d rows streamed", rowCount)
+	return nil
 }
 
 // truncateString tronque une chaîne pour le logging
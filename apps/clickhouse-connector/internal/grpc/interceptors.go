@@ -2,133 +2,117 @@ package grpc
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"time"
 
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	grpcprometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-// unaryInterceptor intercepte les appels unaires pour le logging et la gestion d'erreurs
-func unaryInterceptor(
-	ctx context.Context,
-	req interface{},
-	info *grpc.UnaryServerInfo,
-	handler grpc.UnaryHandler,
-) (interface{}, error) {
-	start := time.Now()
-
-	// Log de la requête entrante
-	log.Printf("gRPC Unary call: %s", info.FullMethod)
-
-	// Appeler le handler
-	resp, err := handler(ctx, req)
-
-	// Log de la réponse
-	duration := time.Since(start)
-	if err != nil {
-		log.Printf("gRPC Unary call: %s - Error: %v - Duration: %v",
-			info.FullMethod, err, duration)
-	} else {
-		log.Printf("gRPC Unary call: %s - Success - Duration: %v",
-			info.FullMethod, duration)
+// buildServerOptions construit la chaîne d'interceptors du serveur gRPC : recovery,
+// logging structuré, validation, auth JWT, métriques Prometheus et tracing OpenTelemetry.
+// L'ordre importe : recovery doit être le plus externe pour capter les panics des autres
+// interceptors, et l'authentification doit précéder le handler métier.
+func buildServerOptions(config *ServerConfig, auth *AuthInterceptor) ([]grpc.ServerOption, error) {
+	logger := slogLogger()
+
+	unaryChain := []grpc.UnaryServerInterceptor{
+		recovery.UnaryServerInterceptor(recovery.WithRecoveryHandlerContext(recoveryHandler)),
+		logging.UnaryServerInterceptor(logger, loggingOptions()...),
+		validator.UnaryServerInterceptor(),
+	}
+	streamChain := []grpc.StreamServerInterceptor{
+		recovery.StreamServerInterceptor(recovery.WithRecoveryHandlerContext(recoveryHandler)),
+		logging.StreamServerInterceptor(logger, loggingOptions()...),
+		validator.StreamServerInterceptor(),
 	}
 
-	return resp, err
-}
-
-// streamInterceptor intercepte les appels streaming pour le logging
-func streamInterceptor(
-	srv interface{},
-	stream grpc.ServerStream,
-	info *grpc.StreamServerInfo,
-	handler grpc.StreamHandler,
-) error {
-	start := time.Now()
+	if config.EnableTracing {
+		unaryChain = append(unaryChain, otelgrpc.UnaryServerInterceptor())
+		streamChain = append(streamChain, otelgrpc.StreamServerInterceptor())
+	}
 
-	// Log de la requête streaming entrante
-	log.Printf("gRPC Stream call: %s", info.FullMethod)
+	if auth != nil {
+		unaryChain = append(unaryChain, auth.Unary)
+		streamChain = append(streamChain, auth.Stream)
+	}
 
-	// Wrapper pour le stream avec logging
-	wrappedStream := &wrappedServerStream{
-		ServerStream: stream,
-		method:       info.FullMethod,
+	if config.EnableMetrics {
+		grpcprometheus.EnableHandlingTimeHistogram()
+		unaryChain = append(unaryChain, grpcprometheus.UnaryServerInterceptor)
+		streamChain = append(streamChain, grpcprometheus.StreamServerInterceptor)
 	}
 
-	// Appeler le handler
-	err := handler(srv, wrappedStream)
+	opts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(config.MaxRecvMsgSize),
+		grpc.MaxSendMsgSize(config.MaxSendMsgSize),
+		grpc.ChainUnaryInterceptor(unaryChain...),
+		grpc.ChainStreamInterceptor(streamChain...),
+	}
 
-	// Log de la fin du stream
-	duration := time.Since(start)
+	creds, err := buildTransportCredentials(config)
 	if err != nil {
-		log.Printf("gRPC Stream call: %s - Error: %v - Duration: %v",
-			info.FullMethod, err, duration)
-	} else {
-		log.Printf("gRPC Stream call: %s - Success - Duration: %v",
-			info.FullMethod, duration)
+		return nil, err
+	}
+	if creds != nil {
+		opts = append(opts, grpc.Creds(creds))
 	}
 
-	return err
-}
-
-// wrappedServerStream wrapper pour ajouter du logging aux streams
-type wrappedServerStream struct {
-	grpc.ServerStream
-	method string
+	return opts, nil
 }
 
-// SendMsg intercepte l'envoi de messages dans le stream
-func (w *wrappedServerStream) SendMsg(m interface{}) error {
-	err := w.ServerStream.SendMsg(m)
-	if err != nil {
-		log.Printf("gRPC Stream %s - SendMsg error: %v", w.method, err)
-	}
-	return err
+// recoveryHandler convertit une panic récupérée en erreur gRPC Internal. La valeur de la
+// panic est loguée côté serveur uniquement : elle peut contenir de l'état interne (requêtes
+// SQL, pointeurs, ...) qui ne doit jamais atteindre le client.
+func recoveryHandler(ctx context.Context, p interface{}) error {
+	slog.ErrorContext(ctx, "recovered from panic", slog.Any("panic", p))
+	return status.Error(codes.Internal, "internal server error")
 }
 
-// RecvMsg intercepte la réception de messages dans le stream
-func (w *wrappedServerStream) RecvMsg(m interface{}) error {
-	err := w.ServerStream.RecvMsg(m)
-	if err != nil {
-		// Ne pas logger les erreurs EOF qui sont normales
-		if status.Code(err) != codes.OutOfRange {
-			log.Printf("gRPC Stream %s - RecvMsg error: %v", w.method, err)
+// slogLogger adapte le logger structuré standard de Go à l'interface attendue
+// par go-grpc-middleware/logging.
+func slogLogger() logging.Logger {
+	return logging.LoggerFunc(func(ctx context.Context, lvl logging.Level, msg string, fields ...any) {
+		attrs := make([]any, 0, len(fields))
+		for i := 0; i+1 < len(fields); i += 2 {
+			attrs = append(attrs, slog.Any(toString(fields[i]), fields[i+1]))
 		}
-	}
-	return err
-}
 
-// recoveryInterceptor récupère les panics et les convertit en erreurs gRPC
-func recoveryInterceptor(
-	ctx context.Context,
-	req interface{},
-	info *grpc.UnaryServerInfo,
-	handler grpc.UnaryHandler,
-) (resp interface{}, err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			log.Printf("gRPC panic recovered in %s: %v", info.FullMethod, r)
-			err = status.Errorf(codes.Internal, "internal server error")
+		switch lvl {
+		case logging.LevelDebug:
+			slog.DebugContext(ctx, msg, attrs...)
+		case logging.LevelInfo:
+			slog.InfoContext(ctx, msg, attrs...)
+		case logging.LevelWarn:
+			slog.WarnContext(ctx, msg, attrs...)
+		case logging.LevelError:
+			slog.ErrorContext(ctx, msg, attrs...)
+		default:
+			slog.InfoContext(ctx, msg, attrs...)
 		}
-	}()
-
-	return handler(ctx, req)
+	})
 }
 
-// streamRecoveryInterceptor récupère les panics dans les streams
-func streamRecoveryInterceptor(
-	srv interface{},
-	stream grpc.ServerStream,
-	info *grpc.StreamServerInfo,
-	handler grpc.StreamHandler,
-) (err error) {
-	defer func() {
-		if r := recover(); r != nil {
-			log.Printf("gRPC stream panic recovered in %s: %v", info.FullMethod, r)
-			err = status.Errorf(codes.Internal, "internal server error")
-		}
-	}()
+func toString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return "field"
+}
 
-	return handler(srv, stream)
+// loggingOptions configure go-grpc-middleware/logging pour logger la méthode, le code
+// de statut et la durée de chaque appel, aligné sur ce qu'exposait l'ancien interceptor.
+func loggingOptions() []logging.Option {
+	return []logging.Option{
+		logging.WithLogOnEvents(logging.StartCall, logging.FinishCall),
+		logging.WithDurationField(func(duration time.Duration) logging.Fields {
+			return logging.Fields{"duration_ms", duration.Milliseconds()}
+		}),
+	}
 }
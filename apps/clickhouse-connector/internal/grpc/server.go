@@ -1,16 +1,22 @@
 package grpc
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 
 	"api-retailers-nest/apps/clickhouse-connector/internal/clickhouse"
+	"api-retailers-nest/apps/clickhouse-connector/internal/schema"
+	"api-retailers-nest/apps/clickhouse-connector/pkg/reattach"
 	pb "api-retailers-nest/packages/proto/go"
 
+	grpcprometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
@@ -21,41 +27,75 @@ import (
 type Server struct {
 	config           *ServerConfig
 	clickhouseClient *clickhouse.Client
+	schemas          *schema.Registry
 	grpcServer       *grpc.Server
 	listener         net.Listener
+	metricsServer    *http.Server
+	tracingShutdown  func(context.Context) error
 }
 
-// NewServer crée un nouveau serveur gRPC
-func NewServer(config *ServerConfig, clickhouseClient *clickhouse.Client) (*Server, error) {
+// NewServer crée un nouveau serveur gRPC. Refuse une configuration TLS/auth incomplète
+// plutôt que de démarrer silencieusement en clair ou sans vérification des tokens.
+func NewServer(config *ServerConfig, clickhouseClient *clickhouse.Client, schemas *schema.Registry) (*Server, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid server config: %w", err)
+	}
+
 	return &Server{
 		config:           config,
 		clickhouseClient: clickhouseClient,
+		schemas:          schemas,
 	}, nil
 }
 
 // Start démarre le serveur gRPC
 func (s *Server) Start() error {
-	// Créer le listener
+	// Créer le listener. En mode reattach, on ignore Host:Port configurés et on écoute sur
+	// un port loopback aléatoire afin de ne jamais entrer en conflit avec une instance
+	// "managée" déjà démarrée sur le port par défaut.
 	address := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
+	if s.config.Reattach {
+		address = "127.0.0.1:0"
+	}
 	listener, err := net.Listen("tcp", address)
 	if err != nil {
 		return fmt.Errorf("failed to listen on %s: %w", address, err)
 	}
 	s.listener = listener
 
-	// Options du serveur gRPC
-	opts := []grpc.ServerOption{
-		grpc.MaxRecvMsgSize(s.config.MaxRecvMsgSize),
-		grpc.MaxSendMsgSize(s.config.MaxSendMsgSize),
-		grpc.UnaryInterceptor(unaryInterceptor),
-		grpc.StreamInterceptor(streamInterceptor),
+	if s.config.EnableTracing {
+		shutdown, err := setupTracing(context.Background(), s.config)
+		if err != nil {
+			return fmt.Errorf("failed to initialize tracing: %w", err)
+		}
+		s.tracingShutdown = shutdown
+		log.Printf("OpenTelemetry tracing enabled, exporting to %s", s.config.OTLPEndpoint)
+	}
+
+	var auth *AuthInterceptor
+	if s.config.EnableAuth {
+		auth, err = NewAuthInterceptor(s.config.JWKSURL, s.config.JWTIssuer, s.config.JWTAudience)
+		if err != nil {
+			return fmt.Errorf("failed to initialize auth interceptor: %w", err)
+		}
+		log.Println("JWT authentication enabled")
 	}
 
-	// Créer le serveur gRPC
+	// Créer le serveur gRPC avec la chaîne d'interceptors (recovery, logging, tracing, auth, métriques)
+	opts, err := buildServerOptions(s.config, auth)
+	if err != nil {
+		return fmt.Errorf("failed to build server options: %w", err)
+	}
 	s.grpcServer = grpc.NewServer(opts...)
 
+	if s.config.ClientCAFile != "" {
+		log.Println("mTLS enabled: client certificates will be required and verified")
+	} else if s.config.TLSCertFile != "" {
+		log.Println("TLS enabled")
+	}
+
 	// Enregistrer le service ClickHouse
-	handler := NewHandler(s.clickhouseClient)
+	handler := NewHandler(s.clickhouseClient, s.schemas)
 	pb.RegisterClickHouseServiceServer(s.grpcServer, handler)
 
 	// Enregistrer le service de health check si activé
@@ -72,7 +112,20 @@ func (s *Server) Start() error {
 		log.Println("gRPC reflection enabled")
 	}
 
-	log.Printf("Starting gRPC server on %s", address)
+	if s.config.EnableMetrics {
+		grpcprometheus.Register(s.grpcServer)
+		s.startMetricsServer()
+	}
+
+	log.Printf("Starting gRPC server on %s", listener.Addr().String())
+
+	// En mode reattach, imprimer les coordonnées de connexion sur stdout pour qu'un
+	// harness de développement puisse s'attacher au processus déjà démarré.
+	if s.config.Reattach {
+		if err := reattach.Print(listener.Addr()); err != nil {
+			return fmt.Errorf("failed to print reattach info: %w", err)
+		}
+	}
 
 	// Démarrer le serveur dans une goroutine
 	go func() {
@@ -87,6 +140,22 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// startMetricsServer démarre le serveur HTTP exposant /metrics au format Prometheus
+func (s *Server) startMetricsServer() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	addr := fmt.Sprintf(":%d", s.config.MetricsPort)
+	s.metricsServer = &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		log.Printf("Starting metrics server on %s", addr)
+		if err := s.metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("Metrics server error: %v", err)
+		}
+	}()
+}
+
 // Stop arrête le serveur gRPC
 func (s *Server) Stop() {
 	log.Println("Shutting down gRPC server...")
@@ -94,6 +163,18 @@ func (s *Server) Stop() {
 	// Arrêter gracieusement le serveur
 	s.grpcServer.GracefulStop()
 
+	if s.metricsServer != nil {
+		if err := s.metricsServer.Shutdown(context.Background()); err != nil {
+			log.Printf("Error shutting down metrics server: %v", err)
+		}
+	}
+
+	if s.tracingShutdown != nil {
+		if err := s.tracingShutdown(context.Background()); err != nil {
+			log.Printf("Error shutting down tracing provider: %v", err)
+		}
+	}
+
 	// Fermer le client ClickHouse
 	if s.clickhouseClient != nil {
 		if err := s.clickhouseClient.Close(); err != nil {
@@ -0,0 +1,42 @@
+package grpc
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// serviceName identifie ce service dans les traces exportées.
+const serviceName = "clickhouse-connector"
+
+// setupTracing construit un TracerProvider OTLP/gRPC pointé sur OTLPEndpoint et l'enregistre
+// comme provider global, pour que otelgrpc (interceptors.go) et contextWithSpan (clickhouse/tracing.go)
+// produisent de vrais spans au lieu d'opérer contre le tracer no-op par défaut. L'appelant doit
+// invoquer la fonction de shutdown retournée pour flusher les spans en attente à l'arrêt.
+func setupTracing(ctx context.Context, config *ServerConfig) (func(context.Context) error, error) {
+	exporter, err := otlptracegrpc.New(ctx,
+		otlptracegrpc.WithEndpoint(config.OTLPEndpoint),
+		otlptracegrpc.WithInsecure(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx, resource.WithAttributes(semconv.ServiceName(serviceName)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tracing resource: %w", err)
+	}
+
+	tracerProvider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(tracerProvider)
+
+	return tracerProvider.Shutdown, nil
+}
@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MapRow peuple msg à partir d'une row ClickHouse en s'appuyant sur EntitySchema et sur
+// la réflexion protobuf, ce qui évite un handler spécifique par type d'entité : ajouter
+// un champ au message + une entrée YAML suffit.
+func MapRow(msg proto.Message, s *EntitySchema, row map[string]interface{}) error {
+	reflected := msg.ProtoReflect()
+	descriptor := reflected.Descriptor()
+
+	for columnName, rawValue := range row {
+		if rawValue == nil {
+			continue
+		}
+
+		field, ok := s.FieldByColumn(columnName)
+		if !ok {
+			// Colonne inconnue du schéma : on l'ignore plutôt que d'échouer toute la row,
+			// pour rester tolérant à une requête qui sélectionne des colonnes additionnelles.
+			continue
+		}
+
+		fd := descriptor.Fields().ByNumber(protoreflect.FieldNumber(field.ProtoField))
+		if fd == nil {
+			return fmt.Errorf("schema %s: no protobuf field numbered %d (column %q)", s.Name, field.ProtoField, columnName)
+		}
+
+		value, err := toProtoValue(field, fd, rawValue)
+		if err != nil {
+			return fmt.Errorf("schema %s: column %q: %w", s.Name, columnName, err)
+		}
+
+		reflected.Set(fd, value)
+	}
+
+	return nil
+}
+
+// toProtoValue convertit une valeur brute issue du driver ClickHouse en protoreflect.Value
+// conforme au FieldType déclaré dans le schéma.
+func toProtoValue(field FieldSpec, fd protoreflect.FieldDescriptor, raw interface{}) (protoreflect.Value, error) {
+	switch field.Type {
+	case FieldTypeString, FieldTypeUUID:
+		s, ok := raw.(string)
+		if !ok {
+			return protoreflect.Value{}, fmt.Errorf("expected string, got %T", raw)
+		}
+		return protoreflect.ValueOfString(s), nil
+
+	case FieldTypeInt64:
+		switch v := raw.(type) {
+		case int64:
+			return protoreflect.ValueOfInt64(v), nil
+		case uint64:
+			return protoreflect.ValueOfInt64(int64(v)), nil
+		case int32:
+			return protoreflect.ValueOfInt64(int64(v)), nil
+		default:
+			return protoreflect.Value{}, fmt.Errorf("expected integer, got %T", raw)
+		}
+
+	case FieldTypeFloat64:
+		switch v := raw.(type) {
+		case float64:
+			return protoreflect.ValueOfFloat64(v), nil
+		case float32:
+			return protoreflect.ValueOfFloat64(float64(v)), nil
+		default:
+			return protoreflect.Value{}, fmt.Errorf("expected float, got %T", raw)
+		}
+
+	case FieldTypeTimestamp:
+		t, ok := raw.(time.Time)
+		if !ok {
+			return protoreflect.Value{}, fmt.Errorf("expected time.Time, got %T", raw)
+		}
+		return protoreflect.ValueOfMessage(timestamppb.New(t).ProtoReflect()), nil
+
+	case FieldTypeNullable:
+		// La valeur est déjà déballée par le driver ; on retombe sur le comportement du
+		// type sous-jacent en traitant raw comme non-nullable à ce stade.
+		return toProtoValue(FieldSpec{Type: nullableElemOrString(field)}, fd, raw)
+
+	case FieldTypeArray:
+		// TODO: supporter les colonnes Array(T) une fois qu'une entité du registre en a besoin ;
+		// la conversion dépend du type d'élément et du Kind protobuf (List vs repeated scalar).
+		return protoreflect.Value{}, fmt.Errorf("array columns are not yet supported (field %s)", fd.FullName())
+
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported field type %q", field.Type)
+	}
+}
+
+func nullableElemOrString(field FieldSpec) FieldType {
+	if field.Elem != "" {
+		return field.Elem
+	}
+	return FieldTypeString
+}
@@ -0,0 +1,49 @@
+package schema
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Registry regroupe les EntitySchema chargés au démarrage, indexés par nom d'entité.
+type Registry struct {
+	schemas map[string]*EntitySchema
+}
+
+// LoadRegistry charge tous les fichiers *.yaml/*.yml d'un répertoire en un Registry.
+func LoadRegistry(dir string) (*Registry, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema directory %s: %w", dir, err)
+	}
+
+	r := &Registry{schemas: make(map[string]*EntitySchema)}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".yaml") && !strings.HasSuffix(name, ".yml") {
+			continue
+		}
+
+		s, err := Load(filepath.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		r.schemas[s.Name] = s
+	}
+
+	return r, nil
+}
+
+// Get retourne l'EntitySchema enregistré pour une entité donnée.
+func (r *Registry) Get(name string) (*EntitySchema, bool) {
+	s, ok := r.schemas[name]
+	return s, ok
+}
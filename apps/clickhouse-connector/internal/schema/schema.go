@@ -0,0 +1,78 @@
+// Package schema décrit, pour chaque type d'entité exposé par le connecteur, comment
+// mapper les colonnes d'un résultat ClickHouse vers les champs d'un message protobuf.
+// Ajouter une colonne à une entité déjà servie ne nécessite alors qu'un champ protobuf et
+// une entrée YAML, sans toucher au code du handler gRPC. Une nouvelle entité réutilise
+// MapRow mais doit encore être servie par une méthode du handler.
+package schema
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldType énumère les types de colonnes déclarables dans un schéma. MapRow ne convertit
+// pas les colonnes "array" : il renvoie une erreur pour ce type.
+type FieldType string
+
+const (
+	FieldTypeString    FieldType = "string"
+	FieldTypeInt64     FieldType = "int64"
+	FieldTypeFloat64   FieldType = "float64"
+	FieldTypeTimestamp FieldType = "timestamp"
+	FieldTypeUUID      FieldType = "uuid"
+	FieldTypeArray     FieldType = "array"
+	FieldTypeNullable  FieldType = "nullable"
+)
+
+// FieldSpec décrit une colonne ClickHouse et le champ protobuf vers lequel elle est mappée.
+type FieldSpec struct {
+	// Column est le nom de la colonne tel que retourné par ClickHouse.
+	Column string `yaml:"column"`
+	// ProtoField est le numéro de champ protobuf (cf. "= N" dans le .proto).
+	ProtoField int32 `yaml:"proto_field"`
+	// Type pilote la conversion Go -> protoreflect.Value.
+	Type FieldType `yaml:"type"`
+	// Elem précise le type sous-jacent pour Type == "nullable" (string si vide) ; il n'est
+	// pas lu pour les autres types.
+	Elem FieldType `yaml:"elem,omitempty"`
+}
+
+// EntitySchema décrit le mapping complet d'une entité (une table/requête -> un message protobuf).
+type EntitySchema struct {
+	// Name identifie l'entité (ex: "retailers") et sert de clé dans le Registry.
+	Name string `yaml:"name"`
+	// Fields liste les colonnes connues, dans n'importe quel ordre.
+	Fields []FieldSpec `yaml:"fields"`
+}
+
+// Load lit et parse un EntitySchema depuis un fichier YAML.
+func Load(path string) (*EntitySchema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema file %s: %w", path, err)
+	}
+
+	var s EntitySchema
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse schema file %s: %w", path, err)
+	}
+
+	if s.Name == "" {
+		return nil, fmt.Errorf("schema file %s is missing a name", path)
+	}
+
+	return &s, nil
+}
+
+// FieldByColumn retourne la spec de colonne correspondante, insensible à la casse.
+func (s *EntitySchema) FieldByColumn(column string) (FieldSpec, bool) {
+	for _, f := range s.Fields {
+		if strings.EqualFold(f.Column, column) {
+			return f, true
+		}
+	}
+	return FieldSpec{}, false
+}
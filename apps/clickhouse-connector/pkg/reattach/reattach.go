@@ -0,0 +1,67 @@
+// Package reattach implémente un mode "unmanaged server" inspiré de
+// TF_REATTACH_PROVIDERS : démarré en mode reattach, le connecteur écoute sur un port
+// loopback aléatoire, imprime ses coordonnées de connexion en JSON sur stdout puis
+// attend, plutôt que d'être géré (lancé/arrêté) par son appelant. Un outil de
+// développement peut alors dialer l'adresse imprimée et s'attacher à un processus
+// déjà démarré sous dlv ou un harness de test, au lieu d'en spawn un nouveau.
+package reattach
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+)
+
+// EnvVar est la variable d'environnement consultée par les consommateurs pour savoir
+// s'ils doivent s'attacher à un processus existant plutôt que d'en démarrer un.
+const EnvVar = "CLICKHOUSE_CONNECTOR_REATTACH"
+
+// Addr décrit l'adresse réseau sur laquelle le connecteur écoute.
+type Addr struct {
+	Network string `json:"network"`
+	String  string `json:"string"`
+}
+
+// Info est le blob JSON imprimé sur stdout par un connecteur démarré en mode reattach.
+type Info struct {
+	Protocol string `json:"protocol"`
+	Addr     Addr   `json:"addr"`
+	Pid      int    `json:"pid"`
+}
+
+// Print construit l'Info décrivant listenerAddr et l'imprime en JSON sur stdout.
+func Print(listenerAddr net.Addr) error {
+	info := Info{
+		Protocol: "grpc",
+		Addr: Addr{
+			Network: listenerAddr.Network(),
+			String:  listenerAddr.String(),
+		},
+		Pid: os.Getpid(),
+	}
+
+	data, err := json.Marshal(info)
+	if err != nil {
+		return fmt.Errorf("failed to marshal reattach info: %w", err)
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
+
+// ParseEnv lit et parse EnvVar pour un consommateur souhaitant s'attacher à un
+// connecteur déjà démarré. Retourne (nil, false, nil) si la variable n'est pas définie.
+func ParseEnv() (*Info, bool, error) {
+	raw := os.Getenv(EnvVar)
+	if raw == "" {
+		return nil, false, nil
+	}
+
+	var info Info
+	if err := json.Unmarshal([]byte(raw), &info); err != nil {
+		return nil, false, fmt.Errorf("failed to parse %s: %w", EnvVar, err)
+	}
+
+	return &info, true, nil
+}
@@ -2,35 +2,111 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"math"
+	"os"
+	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"api-retailers-nest/internal/lb"
 	pb "api-retailers-nest/packages/proto/go"
 
-	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"gopkg.in/yaml.v3"
 )
 
 type BenchmarkConfig struct {
-	ServerAddr  string
-	NumRequests int
-	Concurrency int
+	ServerAddrs []string
+	Stages      []Stage
 	TestQueries []TestQuery
 }
 
+// Stage décrit une phase de charge exécutée séquentiellement par runBenchmark :
+//   - warmup: chauffe les connexions/caches serveur à faible concurrence ; ses résultats
+//     sont jetés.
+//   - rampup: monte linéairement de ConcurrencyStart à ConcurrencyEnd sur DurationSeconds.
+//   - steady: concurrency fixe pendant DurationSeconds, au débit maximal ou, si TargetRPS >
+//     0, cadencée par un leaky bucket au débit cible.
+//   - spike: même mécanique que steady, utilisé pour un burst court à forte concurrence.
+type Stage struct {
+	Type             string `yaml:"type"`
+	DurationSeconds  int    `yaml:"duration_seconds"`
+	Concurrency      int    `yaml:"concurrency"`       // warmup, steady, spike
+	ConcurrencyStart int    `yaml:"concurrency_start"` // rampup
+	ConcurrencyEnd   int    `yaml:"concurrency_end"`   // rampup
+	TargetRPS        int    `yaml:"target_rps"`        // steady ; 0 = non cadencé
+}
+
+// TestPlan est la représentation YAML chargée par -plan, remplaçant les Stages codés en
+// dur par défaut pour permettre de rejouer un profil de charge versionné sans recompiler.
+type TestPlan struct {
+	Stages []Stage `yaml:"stages"`
+}
+
+// loadTestPlan charge et parse un fichier de plan de test YAML.
+func loadTestPlan(path string) ([]Stage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test plan %s: %w", path, err)
+	}
+
+	var plan TestPlan
+	if err := yaml.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse test plan %s: %w", path, err)
+	}
+
+	return plan.Stages, nil
+}
+
+// defaultStages est le profil de charge utilisé quand -plan n'est pas fourni : une seule
+// étape steady, non cadencée, qui approxime l'ancien couple NumRequests/Concurrency codé
+// en dur (100 requêtes à concurrence 5 en quelques secondes).
+func defaultStages() []Stage {
+	return []Stage{
+		{Type: "steady", DurationSeconds: 5, Concurrency: 5},
+	}
+}
+
+// describeStages formate les stages pour l'affichage de démarrage.
+func describeStages(stages []Stage) string {
+	parts := make([]string, 0, len(stages))
+	for _, stage := range stages {
+		parts = append(parts, fmt.Sprintf("%s(%ds)", stage.Type, stage.DurationSeconds))
+	}
+	return strings.Join(parts, " -> ")
+}
+
 type TestQuery struct {
 	Name        string
 	SqlQuery    string
 	Parameters  map[string]string
 	Limit       int32
 	Description string
+
+	// Budget de coût optionnel, comparé au temps d'exécution et aux statistiques de coût
+	// renvoyés par le serveur (ScannedRows/ScannedBytes). Un champ à zéro n'est pas vérifié.
+	MaxEvalTimeMs   int64
+	MaxScannedRows  int64
+	MaxScannedBytes int64
+
+	// Severity détermine l'impact d'une violation de budget : "info"/"warn" ne font que
+	// compter la violation, "fail" fait sortir le process avec un code non nul.
+	Severity string
+
+	// Streaming bascule runStage sur StreamRetailers (stream.Recv() jusqu'à EOF) au lieu de
+	// GetRetailers, pour mesurer le débit en streaming plutôt que la latence unaire sur les
+	// requêtes à résultat large (cf. "large_result" ci-dessous).
+	Streaming bool
 }
 
 type BenchmarkResult struct {
 	QueryName         string
+	StageType         string
 	TotalRequests     int
 	Concurrency       int
 	TotalDuration     time.Duration
@@ -41,14 +117,78 @@ type BenchmarkResult struct {
 	Errors            int
 	AvgServerTime     time.Duration
 	AvgResultCount    float64
+
+	// AvgTTFB/AvgTTLB ne sont renseignés que pour une TestQuery.Streaming : temps moyen
+	// jusqu'à la première, respectivement la dernière, row reçue via StreamRetailers.
+	AvgTTFB time.Duration
+	AvgTTLB time.Duration
+
+	// SortedDurations est la latence client de chaque requête, triée croissant. Conservée
+	// le temps d'imprimer l'histogramme ; jamais persistée au-delà du run.
+	SortedDurations []time.Duration
+	P50             time.Duration
+	P90             time.Duration
+	P95             time.Duration
+	P99             time.Duration
+
+	// ServerP50..ServerP99 sont calculés sur ExecutionTimeMs (temps mesuré côté serveur),
+	// à distinguer de P50..P99 qui incluent le round-trip réseau.
+	ServerP50 time.Duration
+	ServerP90 time.Duration
+	ServerP95 time.Duration
+	ServerP99 time.Duration
+
+	// Histogram bin la latence client en ~10 buckets linéaires sur [min, max].
+	Histogram []HistogramBucket
+
+	// ErrorBreakdown compte les erreurs par catégorie : code gRPC (ex. "Unavailable") pour
+	// les erreurs de transport, "response_error" pour un GetRetailersResponse.Error non vide.
+	ErrorBreakdown map[string]int
+
+	// BudgetViolations compte les requêtes ayant dépassé au moins un budget de coût défini
+	// sur la TestQuery (MaxEvalTimeMs/MaxScannedRows/MaxScannedBytes), indépendamment des
+	// erreurs de transport. BudgetFailed est vrai si la TestQuery est de Severity "fail" et
+	// qu'au moins une violation a eu lieu ; le process doit alors sortir avec un code non nul.
+	BudgetViolations int
+	BudgetSeverity   string
+	BudgetFailed     bool
+
+	// PerEndpoint agrège, pour cette requête, les métriques par endpoint sollicité par le
+	// Balancer (utile quand ServerAddrs contient plusieurs répliques).
+	PerEndpoint map[string]*EndpointStats
+}
+
+// EndpointStats accumule les métriques observées pour un endpoint donné.
+type EndpointStats struct {
+	Requests      int
+	Errors        int
+	TotalDuration time.Duration
+}
+
+// HistogramBucket représente un bucket de latence dans un histogramme ASCII.
+type HistogramBucket struct {
+	Min   time.Duration
+	Max   time.Duration
+	Count int
 }
 
 func main() {
+	planPath := flag.String("plan", "", "path to a YAML test plan (warmup/rampup/steady/spike stages); defaults to a single steady stage")
+	flag.Parse()
+
+	stages := defaultStages()
+	if *planPath != "" {
+		loaded, err := loadTestPlan(*planPath)
+		if err != nil {
+			log.Fatalf("Failed to load test plan: %v", err)
+		}
+		stages = loaded
+	}
+
 	// Configuration du benchmark
 	config := BenchmarkConfig{
-		ServerAddr:  "localhost:50051",
-		NumRequests: 100, // Réduit pour des tests plus rapides
-		Concurrency: 5,   // Réduit aussi
+		ServerAddrs: []string{"localhost:50051"},
+		Stages:      stages,
 		TestQueries: []TestQuery{
 			{
 				Name:        "simple_limit",
@@ -69,6 +209,13 @@ func main() {
 				Limit:       1000,
 				Description: "Requête avec résultat plus large",
 			},
+			{
+				Name:        "large_result_streaming",
+				SqlQuery:    "SELECT id, name FROM retailers",
+				Limit:       1000,
+				Description: "Même requête que large_result, consommée via StreamRetailers",
+				Streaming:   true,
+			},
 			{
 				Name:        "date_filter",
 				SqlQuery:    "SELECT * FROM retailers WHERE created_at > toDateTime(?)",
@@ -93,35 +240,40 @@ func main() {
 
 	fmt.Println("🚀 ClickHouse gRPC Server Benchmark")
 	fmt.Println("=====================================")
-	fmt.Printf("Server: %s\n", config.ServerAddr)
-	fmt.Printf("Requests per query: %d\n", config.NumRequests)
-	fmt.Printf("Concurrency: %d\n", config.Concurrency)
+	fmt.Printf("Servers: %s\n", strings.Join(config.ServerAddrs, ", "))
+	fmt.Printf("Stages: %s\n", describeStages(config.Stages))
 	fmt.Println()
 
-	// Connexion au serveur gRPC
-	conn, err := grpc.Dial(config.ServerAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	if err != nil {
-		log.Fatalf("Failed to connect to server: %v", err)
-	}
-	defer conn.Close()
-
-	client := pb.NewClickHouseServiceClient(conn)
+	// Pool de connexions gRPC keyed par adresse, partagé par toutes les requêtes du run.
+	pool := lb.NewPool()
+	defer pool.CloseAll()
 
-	// Test de sanité
+	// Test de sanité sur chaque endpoint : un endpoint en panne au démarrage doit être
+	// signalé immédiatement plutôt que de faire échouer silencieusement des requêtes plus tard.
 	fmt.Println("🔍 Health Check...")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	for _, addr := range config.ServerAddrs {
+		conn, err := pool.Get(addr)
+		if err != nil {
+			log.Fatalf("Failed to connect to %s: %v", addr, err)
+		}
 
-	health, err := client.HealthCheck(ctx, &pb.HealthCheckRequest{})
-	if err != nil {
-		log.Fatalf("Health check failed: %v", err)
-	}
+		client := pb.NewClickHouseServiceClient(conn)
 
-	if !health.Healthy {
-		log.Fatalf("Server is not healthy: %s", health.Error)
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		health, err := client.HealthCheck(ctx, &pb.HealthCheckRequest{})
+		cancel()
+		if err != nil {
+			log.Fatalf("Health check failed for %s: %v", addr, err)
+		}
+		if !health.Healthy {
+			log.Fatalf("Server %s is not healthy: %s", addr, health.Error)
+		}
+
+		fmt.Printf("✅ %s healthy (uptime: %ds, version: %s)\n", addr, health.UptimeSeconds, health.Version)
 	}
+	fmt.Println()
 
-	fmt.Printf("✅ Server healthy (uptime: %ds, version: %s)\n\n", health.UptimeSeconds, health.Version)
+	balancer := lb.New(config.ServerAddrs)
 
 	// Exécuter les benchmarks
 	var allResults []BenchmarkResult
@@ -129,72 +281,180 @@ func main() {
 	for _, query := range config.TestQueries {
 		fmt.Printf("📊 Testing: %s - %s\n", query.Name, query.Description)
 
-		result := runBenchmark(client, query, config.NumRequests, config.Concurrency)
-		allResults = append(allResults, result)
+		stageResults := runBenchmark(pool, balancer, query, config.Stages)
+		allResults = append(allResults, stageResults...)
 
-		printResult(result)
-		fmt.Println()
+		for _, result := range stageResults {
+			printResult(result)
+			fmt.Println()
+		}
 	}
 
 	// Résumé global
 	printSummary(allResults)
+
+	// Sortir en échec si un budget de coût "fail" a été dépassé, pour servir de gate CI.
+	for _, result := range allResults {
+		if result.BudgetFailed {
+			fmt.Println("\n❌ One or more fail-severity cost budgets were breached")
+			os.Exit(1)
+		}
+	}
 }
 
-func runBenchmark(client pb.ClickHouseServiceClient, query TestQuery, numRequests, concurrency int) BenchmarkResult {
-	var wg sync.WaitGroup
-	var mu sync.Mutex
+// runBenchmark exécute stages séquentiellement pour query (warmup, rampup,
+// steady, spike) et retourne un BenchmarkResult par stage. Les résultats d'un stage
+// warmup sont jetés : son seul rôle est de chauffer les connexions et caches serveur.
+func runBenchmark(pool *lb.Pool, balancer *lb.Balancer, query TestQuery, stages []Stage) []BenchmarkResult {
+	var results []BenchmarkResult
 
-	durations := make([]time.Duration, 0, numRequests)
-	serverTimes := make([]time.Duration, 0, numRequests)
-	resultCounts := make([]int, 0, numRequests)
-	errors := 0
+	for _, stage := range stages {
+		result := runStage(pool, balancer, query, stage)
+		if stage.Type == "warmup" {
+			continue
+		}
+		results = append(results, result)
+	}
 
-	// Canal pour limiter la concurrence
-	semaphore := make(chan struct{}, concurrency)
+	return results
+}
 
-	start := time.Now()
+// runStage exécute une unique Stage pendant sa durée configurée. La concurrence active
+// est gouvernée par stageConcurrencyAt (constante, ou linéaire pour un rampup) ; en mode
+// steady avec TargetRPS > 0, un leaky bucket cadence les requêtes au débit cible.
+func runStage(pool *lb.Pool, balancer *lb.Balancer, query TestQuery, stage Stage) BenchmarkResult {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
 
-	for i := 0; i < numRequests; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
+	var durations []time.Duration
+	var serverTimes []time.Duration
+	var resultCounts []int
+	var ttfbs []time.Duration
+	var ttlbs []time.Duration
+	errors := 0
+	errorBreakdown := make(map[string]int)
+	budgetViolations := 0
+	budgetFailed := false
 
-			// Acquérir le semaphore
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
+	perEndpoint := make(map[string]*EndpointStats, len(balancer.Addrs()))
+	for _, addr := range balancer.Addrs() {
+		perEndpoint[addr] = &EndpointStats{}
+	}
 
-			reqStart := time.Now()
+	duration := time.Duration(stage.DurationSeconds) * time.Second
+	maxConcurrency := stageMaxConcurrency(stage)
 
-			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-			defer cancel()
+	stop := make(chan struct{})
+	timer := time.AfterFunc(duration, func() { close(stop) })
+	defer timer.Stop()
 
-			resp, err := client.GetRetailers(ctx, &pb.GetRetailersRequest{
-				SqlQuery:   query.SqlQuery,
-				Parameters: query.Parameters,
-				Limit:      query.Limit,
-			})
+	var tokens <-chan struct{}
+	if stage.TargetRPS > 0 {
+		tokens = newPacer(stage.TargetRPS, stop)
+	}
 
-			reqDuration := time.Since(reqStart)
+	start := time.Now()
 
-			mu.Lock()
-			durations = append(durations, reqDuration)
+	for workerIdx := 0; workerIdx < maxConcurrency; workerIdx++ {
+		wg.Add(1)
+		go func(workerIdx int) {
+			defer wg.Done()
 
-			if err != nil {
-				errors++
-			} else if resp.Error != "" {
-				errors++
-			} else {
-				serverTimes = append(serverTimes, time.Duration(resp.ExecutionTimeMs)*time.Millisecond)
-				resultCounts = append(resultCounts, int(resp.Count))
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+
+				// Le rampup démarre avec moins de workers actifs que maxConcurrency ;
+				// ceux pas encore "allumés" patientent plutôt que d'envoyer des requêtes.
+				if !stageConcurrencyAllows(stage, workerIdx, time.Since(start)) {
+					select {
+					case <-time.After(50 * time.Millisecond):
+						continue
+					case <-stop:
+						return
+					}
+				}
+
+				if tokens != nil {
+					select {
+					case <-tokens:
+					case <-stop:
+						return
+					}
+				}
+
+				// Choisir l'endpoint le moins chargé (EWMA latence * (1 + in-flight))
+				// avant d'ouvrir la requête, et reporter la latence observée ensuite.
+				addr := balancer.Pick()
+				conn, err := pool.Get(addr)
+
+				reqStart := time.Now()
+
+				var resp *pb.GetRetailersResponse
+				var ttfb, ttlb time.Duration
+				if err == nil {
+					client := pb.NewClickHouseServiceClient(conn)
+					if query.Streaming {
+						ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+						ttfb, ttlb, err = runStreamingRequest(ctx, client, query)
+						cancel()
+					} else {
+						ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+						resp, err = client.GetRetailers(ctx, &pb.GetRetailersRequest{
+							SqlQuery:   query.SqlQuery,
+							Parameters: query.Parameters,
+							Limit:      query.Limit,
+						})
+						cancel()
+					}
+				}
+
+				reqDuration := time.Since(reqStart)
+				balancer.Done(addr, reqDuration)
+
+				mu.Lock()
+				durations = append(durations, reqDuration)
+
+				endpoint := perEndpoint[addr]
+				endpoint.Requests++
+				endpoint.TotalDuration += reqDuration
+
+				switch {
+				case err != nil:
+					errors++
+					errorBreakdown[status.Code(err).String()]++
+					endpoint.Errors++
+				case query.Streaming:
+					ttfbs = append(ttfbs, ttfb)
+					ttlbs = append(ttlbs, ttlb)
+				case resp.Error != "":
+					errors++
+					errorBreakdown["response_error"]++
+					endpoint.Errors++
+				default:
+					serverTimes = append(serverTimes, time.Duration(resp.ExecutionTimeMs)*time.Millisecond)
+					resultCounts = append(resultCounts, int(resp.Count))
+
+					if breachesBudget(query, resp) {
+						budgetViolations++
+						if query.Severity == "fail" {
+							budgetFailed = true
+						}
+					}
+				}
+				mu.Unlock()
 			}
-			mu.Unlock()
-		}()
+		}(workerIdx)
 	}
 
 	wg.Wait()
 	totalDuration := time.Since(start)
 
 	// Calculer les statistiques
+	numRequests := len(durations)
 	avgDuration := calculateAverage(durations)
 	minDuration := calculateMin(durations)
 	maxDuration := calculateMax(durations)
@@ -202,10 +462,14 @@ func runBenchmark(client pb.ClickHouseServiceClient, query TestQuery, numRequest
 	avgResultCount := calculateAverageInt(resultCounts)
 	rps := float64(numRequests) / totalDuration.Seconds()
 
+	sortedDurations := sortedCopy(durations)
+	sortedServerTimes := sortedCopy(serverTimes)
+
 	return BenchmarkResult{
 		QueryName:         query.Name,
+		StageType:         stage.Type,
 		TotalRequests:     numRequests,
-		Concurrency:       concurrency,
+		Concurrency:       maxConcurrency,
 		TotalDuration:     totalDuration,
 		AvgDuration:       avgDuration,
 		MinDuration:       minDuration,
@@ -214,36 +478,220 @@ func runBenchmark(client pb.ClickHouseServiceClient, query TestQuery, numRequest
 		Errors:            errors,
 		AvgServerTime:     avgServerTime,
 		AvgResultCount:    avgResultCount,
+		AvgTTFB:           calculateAverage(ttfbs),
+		AvgTTLB:           calculateAverage(ttlbs),
+		SortedDurations:   sortedDurations,
+		P50:               percentile(sortedDurations, 0.5),
+		P90:               percentile(sortedDurations, 0.9),
+		P95:               percentile(sortedDurations, 0.95),
+		P99:               percentile(sortedDurations, 0.99),
+		ServerP50:         percentile(sortedServerTimes, 0.5),
+		ServerP90:         percentile(sortedServerTimes, 0.9),
+		ServerP95:         percentile(sortedServerTimes, 0.95),
+		ServerP99:         percentile(sortedServerTimes, 0.99),
+		Histogram:         buildHistogram(sortedDurations),
+		ErrorBreakdown:    errorBreakdown,
+		BudgetViolations:  budgetViolations,
+		BudgetSeverity:    query.Severity,
+		BudgetFailed:      budgetFailed,
+		PerEndpoint:       perEndpoint,
+	}
+}
+
+// stageMaxConcurrency retourne la concurrence maximale atteinte par stage, utilisée pour
+// dimensionner le pool de workers (fixe pour warmup/steady/spike, ConcurrencyEnd pour un
+// rampup qui termine plus haut qu'il ne commence).
+func stageMaxConcurrency(stage Stage) int {
+	if stage.Type != "rampup" {
+		return stage.Concurrency
+	}
+	if stage.ConcurrencyEnd > stage.ConcurrencyStart {
+		return stage.ConcurrencyEnd
+	}
+	return stage.ConcurrencyStart
+}
+
+// stageConcurrencyAllows indique si le worker workerIdx doit être actif à l'instant elapsed
+// de la stage. Pour un rampup, la concurrence autorisée croît linéairement entre
+// ConcurrencyStart et ConcurrencyEnd sur toute la durée du stage.
+func stageConcurrencyAllows(stage Stage, workerIdx int, elapsed time.Duration) bool {
+	if stage.Type != "rampup" {
+		return workerIdx < stage.Concurrency
+	}
+
+	duration := time.Duration(stage.DurationSeconds) * time.Second
+	if duration <= 0 {
+		return workerIdx < stage.ConcurrencyEnd
+	}
+
+	fraction := float64(elapsed) / float64(duration)
+	if fraction > 1 {
+		fraction = 1
+	}
+
+	current := float64(stage.ConcurrencyStart) + fraction*float64(stage.ConcurrencyEnd-stage.ConcurrencyStart)
+	return workerIdx < int(current)
+}
+
+// newPacer implémente un leaky bucket : un jeton est poussé dans le channel retourné
+// toutes les 1/rps secondes, et les workers lisent un jeton avant d'envoyer une requête
+// pour caler le débit global sur rps plutôt que sur la concurrence disponible.
+func newPacer(rps int, stop <-chan struct{}) <-chan struct{} {
+	tokens := make(chan struct{})
+
+	go func() {
+		defer close(tokens)
+
+		interval := time.Second / time.Duration(rps)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				select {
+				case tokens <- struct{}{}:
+				case <-stop:
+					return
+				}
+			}
+		}
+	}()
+
+	return tokens
+}
+
+// runStreamingRequest exécute StreamRetailers pour query et mesure le temps écoulé jusqu'à la
+// première row reçue (TTFB) et jusqu'à la dernière (TTLB), en consommant le flux via
+// stream.Recv() jusqu'à io.EOF.
+func runStreamingRequest(ctx context.Context, client pb.ClickHouseServiceClient, query TestQuery) (ttfb, ttlb time.Duration, err error) {
+	start := time.Now()
+
+	stream, err := client.StreamRetailers(ctx, &pb.GetRetailersRequest{
+		SqlQuery:   query.SqlQuery,
+		Parameters: query.Parameters,
+		Limit:      query.Limit,
+	})
+	if err != nil {
+		return 0, 0, err
 	}
+
+	rowCount := 0
+	for {
+		_, recvErr := stream.Recv()
+		if recvErr == io.EOF {
+			break
+		}
+		if recvErr != nil {
+			return ttfb, ttlb, recvErr
+		}
+
+		if rowCount == 0 {
+			ttfb = time.Since(start)
+		}
+		ttlb = time.Since(start)
+		rowCount++
+	}
+
+	return ttfb, ttlb, nil
+}
+
+// breachesBudget compare une réponse serveur aux budgets de coût optionnels de query.
+// Un champ de budget à zéro n'est pas vérifié.
+func breachesBudget(query TestQuery, resp *pb.GetRetailersResponse) bool {
+	if query.MaxEvalTimeMs > 0 && resp.ExecutionTimeMs > query.MaxEvalTimeMs {
+		return true
+	}
+	if query.MaxScannedRows > 0 && resp.ScannedRows > query.MaxScannedRows {
+		return true
+	}
+	if query.MaxScannedBytes > 0 && resp.ScannedBytes > query.MaxScannedBytes {
+		return true
+	}
+	return false
 }
 
 func printResult(result BenchmarkResult) {
+	if result.StageType != "" {
+		fmt.Printf("  Stage: %s\n", result.StageType)
+	}
 	fmt.Printf("  Total time: %v\n", result.TotalDuration)
 	fmt.Printf("  Requests/sec: %.2f\n", result.RequestsPerSecond)
 	fmt.Printf("  Avg latency: %v (server: %v)\n", result.AvgDuration, result.AvgServerTime)
 	fmt.Printf("  Min/Max latency: %v / %v\n", result.MinDuration, result.MaxDuration)
+	fmt.Printf("  Percentiles (client): p50=%v p90=%v p95=%v p99=%v\n",
+		result.P50, result.P90, result.P95, result.P99)
+	fmt.Printf("  Percentiles (server): p50=%v p90=%v p95=%v p99=%v\n",
+		result.ServerP50, result.ServerP90, result.ServerP95, result.ServerP99)
 	fmt.Printf("  Avg result count: %.1f\n", result.AvgResultCount)
+	if result.AvgTTFB > 0 || result.AvgTTLB > 0 {
+		fmt.Printf("  Streaming: avg TTFB=%v avg TTLB=%v\n", result.AvgTTFB, result.AvgTTLB)
+	}
 	fmt.Printf("  Errors: %d/%d (%.2f%%)\n",
 		result.Errors, result.TotalRequests,
 		float64(result.Errors)/float64(result.TotalRequests)*100)
+	if len(result.ErrorBreakdown) > 0 {
+		fmt.Println("  Error breakdown:")
+		for category, count := range result.ErrorBreakdown {
+			fmt.Printf("    %-20s %d\n", category, count)
+		}
+	}
+	if result.BudgetSeverity != "" {
+		fmt.Printf("  Budget violations: %d/%d (severity=%s)\n",
+			result.BudgetViolations, result.TotalRequests, result.BudgetSeverity)
+	}
+	printHistogram(result.Histogram)
+}
+
+// printHistogram affiche un histogramme ASCII de la distribution de latence client.
+func printHistogram(buckets []HistogramBucket) {
+	if len(buckets) == 0 {
+		return
+	}
+
+	maxCount := 0
+	for _, b := range buckets {
+		if b.Count > maxCount {
+			maxCount = b.Count
+		}
+	}
+
+	const barWidth = 40
+	fmt.Println("  Latency histogram:")
+	for _, b := range buckets {
+		barLen := 0
+		if maxCount > 0 {
+			barLen = b.Count * barWidth / maxCount
+		}
+		fmt.Printf("    %10v - %-10v %5d %s\n", b.Min, b.Max, b.Count, strings.Repeat("#", barLen))
+	}
 }
 
 func printSummary(results []BenchmarkResult) {
 	fmt.Println("📈 BENCHMARK SUMMARY")
 	fmt.Println("===================")
-	fmt.Printf("%-20s %10s %10s %15s %10s %10s\n",
-		"Query", "RPS", "Avg Lat", "Server Time", "Results", "Errors")
-	fmt.Println(strings.Repeat("-", 85))
+	fmt.Printf("%-20s %10s %10s %10s %10s %15s %10s %10s\n",
+		"Query", "RPS", "Avg Lat", "P95", "P99", "Server Time", "Results", "Errors")
+	fmt.Println(strings.Repeat("-", 105))
 
 	var totalRPS float64
 	var totalErrors int
 	var totalRequests int
 
 	for _, result := range results {
-		fmt.Printf("%-20s %10.1f %10v %15v %10.0f %9d%%\n",
-			result.QueryName,
+		label := result.QueryName
+		if result.StageType != "" {
+			label = fmt.Sprintf("%s/%s", result.QueryName, result.StageType)
+		}
+
+		fmt.Printf("%-20s %10.1f %10v %10v %10v %15v %10.0f %9d%%\n",
+			label,
 			result.RequestsPerSecond,
 			result.AvgDuration,
+			result.P95,
+			result.P99,
 			result.AvgServerTime,
 			result.AvgResultCount,
 			int(float64(result.Errors)/float64(result.TotalRequests)*100))
@@ -253,11 +701,13 @@ func printSummary(results []BenchmarkResult) {
 		totalRequests += result.TotalRequests
 	}
 
-	fmt.Println(strings.Repeat("-", 85))
+	fmt.Println(strings.Repeat("-", 105))
 	fmt.Printf("Total RPS: %.1f | Total Errors: %d/%d (%.2f%%)\n",
 		totalRPS, totalErrors, totalRequests,
 		float64(totalErrors)/float64(totalRequests)*100)
 
+	printEndpointSummary(results)
+
 	// Recommandations
 	fmt.Println("\n💡 RECOMMENDATIONS")
 	fmt.Println("==================")
@@ -294,6 +744,52 @@ func printSummary(results []BenchmarkResult) {
 	}
 }
 
+// printEndpointSummary agrège les EndpointStats de tous les résultats (toutes requêtes
+// confondues) et affiche, par endpoint sollicité par le Balancer, le RPS, la latence
+// moyenne et le taux d'erreur.
+func printEndpointSummary(results []BenchmarkResult) {
+	totals := make(map[string]*EndpointStats)
+	var addrs []string
+
+	for _, result := range results {
+		for addr, stats := range result.PerEndpoint {
+			if _, ok := totals[addr]; !ok {
+				totals[addr] = &EndpointStats{}
+				addrs = append(addrs, addr)
+			}
+			totals[addr].Requests += stats.Requests
+			totals[addr].Errors += stats.Errors
+			totals[addr].TotalDuration += stats.TotalDuration
+		}
+	}
+
+	if len(addrs) == 0 {
+		return
+	}
+
+	sort.Strings(addrs)
+
+	fmt.Println("\n📡 PER-ENDPOINT SUMMARY")
+	fmt.Println("=======================")
+	fmt.Printf("%-30s %10s %12s %10s\n", "Endpoint", "Requests", "Avg Lat", "Err Rate")
+	fmt.Println(strings.Repeat("-", 66))
+
+	for _, addr := range addrs {
+		stats := totals[addr]
+		var avgLatency time.Duration
+		if stats.Requests > 0 {
+			avgLatency = stats.TotalDuration / time.Duration(stats.Requests)
+		}
+
+		errRate := 0.0
+		if stats.Requests > 0 {
+			errRate = float64(stats.Errors) / float64(stats.Requests) * 100
+		}
+
+		fmt.Printf("%-30s %10d %12v %9.2f%%\n", addr, stats.Requests, avgLatency, errRate)
+	}
+}
+
 // Utilitaires pour calculs statistiques
 func calculateAverage(durations []time.Duration) time.Duration {
 	if len(durations) == 0 {
@@ -332,6 +828,64 @@ func calculateMax(durations []time.Duration) time.Duration {
 	return max
 }
 
+// sortedCopy retourne une copie triée croissant de durations, sans modifier le slice d'origine.
+func sortedCopy(durations []time.Duration) []time.Duration {
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted
+}
+
+// percentile retourne la valeur au quantile q (ex. 0.95 pour p95) d'un slice déjà trié.
+func percentile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(sorted) {
+		idx = len(sorted) - 1
+	}
+	return sorted[idx]
+}
+
+// buildHistogram bin un slice trié de latences en 10 buckets linéaires sur [min, max], ou un
+// bucket unique si toutes les valeurs sont égales.
+func buildHistogram(sorted []time.Duration) []HistogramBucket {
+	if len(sorted) == 0 {
+		return nil
+	}
+
+	min := sorted[0]
+	max := sorted[len(sorted)-1]
+
+	if max == min {
+		return []HistogramBucket{{Min: min, Max: max, Count: len(sorted)}}
+	}
+
+	const numBuckets = 10
+	width := (max - min) / numBuckets
+
+	buckets := make([]HistogramBucket, numBuckets)
+	for i := range buckets {
+		buckets[i].Min = min + time.Duration(i)*width
+		buckets[i].Max = min + time.Duration(i+1)*width
+	}
+	buckets[numBuckets-1].Max = max
+
+	for _, d := range sorted {
+		idx := int((d - min) / width)
+		if idx > numBuckets-1 {
+			idx = numBuckets - 1
+		}
+		buckets[idx].Count++
+	}
+
+	return buckets
+}
+
 func calculateAverageInt(counts []int) float64 {
 	if len(counts) == 0 {
 		return 0
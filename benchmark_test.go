@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	sorted := []time.Duration{
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		30 * time.Millisecond,
+		40 * time.Millisecond,
+		50 * time.Millisecond,
+		60 * time.Millisecond,
+		70 * time.Millisecond,
+		80 * time.Millisecond,
+		90 * time.Millisecond,
+		100 * time.Millisecond,
+	}
+
+	tests := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{q: 0.5, want: 50 * time.Millisecond},
+		{q: 0.9, want: 90 * time.Millisecond},
+		{q: 0.95, want: 100 * time.Millisecond},
+		{q: 0.99, want: 100 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		if got := percentile(sorted, tt.q); got != tt.want {
+			t.Fatalf("percentile(q=%v) = %v, want %v", tt.q, got, tt.want)
+		}
+	}
+}
+
+func TestPercentile_Empty(t *testing.T) {
+	if got := percentile(nil, 0.5); got != 0 {
+		t.Fatalf("percentile(nil) = %v, want 0", got)
+	}
+}
+
+func TestSortedCopy(t *testing.T) {
+	original := []time.Duration{30 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond}
+	sorted := sortedCopy(original)
+
+	want := []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond}
+	for i := range want {
+		if sorted[i] != want[i] {
+			t.Fatalf("sortedCopy()[%d] = %v, want %v", i, sorted[i], want[i])
+		}
+	}
+
+	// Must not mutate the original slice's order.
+	if original[0] != 30*time.Millisecond {
+		t.Fatalf("sortedCopy() mutated its input: original[0] = %v, want %v", original[0], 30*time.Millisecond)
+	}
+}
+
+func TestBuildHistogram(t *testing.T) {
+	sorted := []time.Duration{
+		0 * time.Millisecond,
+		10 * time.Millisecond,
+		20 * time.Millisecond,
+		100 * time.Millisecond,
+	}
+
+	buckets := buildHistogram(sorted)
+	if len(buckets) != 10 {
+		t.Fatalf("buildHistogram() returned %d buckets, want 10", len(buckets))
+	}
+
+	totalCount := 0
+	for _, b := range buckets {
+		totalCount += b.Count
+	}
+	if totalCount != len(sorted) {
+		t.Fatalf("buildHistogram() bucket counts sum to %d, want %d", totalCount, len(sorted))
+	}
+
+	if buckets[0].Min != sorted[0] {
+		t.Fatalf("first bucket Min = %v, want %v", buckets[0].Min, sorted[0])
+	}
+	if buckets[len(buckets)-1].Max != sorted[len(sorted)-1] {
+		t.Fatalf("last bucket Max = %v, want %v", buckets[len(buckets)-1].Max, sorted[len(sorted)-1])
+	}
+}
+
+func TestBuildHistogram_AllEqual(t *testing.T) {
+	sorted := []time.Duration{50 * time.Millisecond, 50 * time.Millisecond, 50 * time.Millisecond}
+
+	buckets := buildHistogram(sorted)
+	if len(buckets) != 1 {
+		t.Fatalf("buildHistogram() returned %d buckets, want 1 for equal values", len(buckets))
+	}
+	if buckets[0].Count != len(sorted) {
+		t.Fatalf("buildHistogram() bucket Count = %d, want %d", buckets[0].Count, len(sorted))
+	}
+}
+
+func TestBuildHistogram_Empty(t *testing.T) {
+	if got := buildHistogram(nil); got != nil {
+		t.Fatalf("buildHistogram(nil) = %v, want nil", got)
+	}
+}
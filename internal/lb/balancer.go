@@ -0,0 +1,104 @@
+// Package lb implémente un répartiteur de charge "look-aside" pour le client de
+// benchmark, analogue au LookAsideBalancer de Milvus : chaque endpoint est noté par sa
+// latence moyenne (EWMA) pondérée par son nombre de requêtes en vol, et le pool de
+// connexions associé évite de redialer à chaque sélection.
+package lb
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// staleAfter est le délai d'inactivité après lequel l'EWMA d'un endpoint est remise à
+// zéro, pour qu'un nœud auparavant lent soit re-sondé plutôt que jamais revisité.
+const staleAfter = 1 * time.Second
+
+type endpointState struct {
+	ewmaLatencyMs float64
+	inflight      int
+	lastUpdate    time.Time
+}
+
+// Balancer sélectionne, parmi un ensemble fixe d'endpoints, celui de score le plus bas
+// (ewmaLatencyMs * (1 + inflight)).
+type Balancer struct {
+	mu        sync.Mutex
+	endpoints map[string]*endpointState
+	order     []string
+}
+
+// New crée un Balancer pour addrs, tous initialisés à un score neutre (EWMA nulle, aucune
+// requête en vol).
+func New(addrs []string) *Balancer {
+	endpoints := make(map[string]*endpointState, len(addrs))
+	for _, addr := range addrs {
+		endpoints[addr] = &endpointState{}
+	}
+	return &Balancer{endpoints: endpoints, order: addrs}
+}
+
+// Addrs retourne les endpoints gérés par ce Balancer, dans leur ordre d'enregistrement.
+func (b *Balancer) Addrs() []string {
+	addrs := make([]string, len(b.order))
+	copy(addrs, b.order)
+	return addrs
+}
+
+// Pick sélectionne l'endpoint de score le plus bas, les égalités étant départagées
+// aléatoirement, et incrémente son compteur in-flight. L'appelant doit appeler Done une
+// fois la requête terminée.
+func (b *Balancer) Pick() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	bestScore := -1.0
+	var ties []string
+
+	for _, addr := range b.order {
+		state := b.endpoints[addr]
+
+		if !state.lastUpdate.IsZero() && now.Sub(state.lastUpdate) > staleAfter {
+			state.ewmaLatencyMs = 0
+		}
+
+		score := state.ewmaLatencyMs * (1 + float64(state.inflight))
+		switch {
+		case bestScore < 0 || score < bestScore:
+			bestScore = score
+			ties = []string{addr}
+		case score == bestScore:
+			ties = append(ties, addr)
+		}
+	}
+
+	best := ties[rand.Intn(len(ties))]
+	b.endpoints[best].inflight++
+	return best
+}
+
+// Done enregistre la latence observed pour addr (EWMA: new = 0.7*old + 0.3*observed ; la
+// première observation initialise directement l'EWMA à observed) et décrémente son
+// compteur in-flight.
+func (b *Balancer) Done(addr string, observed time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, ok := b.endpoints[addr]
+	if !ok {
+		return
+	}
+
+	observedMs := float64(observed.Milliseconds())
+	if state.lastUpdate.IsZero() {
+		state.ewmaLatencyMs = observedMs
+	} else {
+		state.ewmaLatencyMs = 0.7*state.ewmaLatencyMs + 0.3*observedMs
+	}
+	state.lastUpdate = time.Now()
+
+	if state.inflight > 0 {
+		state.inflight--
+	}
+}
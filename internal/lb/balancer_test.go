@@ -0,0 +1,80 @@
+package lb
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBalancer_PickPrefersLowerEWMA(t *testing.T) {
+	b := New([]string{"a:1", "b:1"})
+
+	// "a" observes a fast response, "b" a slow one: subsequent picks should favor "a".
+	b.Done("a:1", 10*time.Millisecond)
+	b.Done("b:1", 500*time.Millisecond)
+
+	for i := 0; i < 10; i++ {
+		if got := b.Pick(); got != "a:1" {
+			t.Fatalf("Pick() = %q, want %q (lower EWMA)", got, "a:1")
+		}
+		b.Done("a:1", 10*time.Millisecond)
+	}
+}
+
+func TestBalancer_PickAccountsForInflight(t *testing.T) {
+	b := New([]string{"a:1", "b:1"})
+
+	// Equal EWMA, but "a" has an in-flight request: "b" should be preferred.
+	b.Done("a:1", 50*time.Millisecond)
+	b.Done("b:1", 50*time.Millisecond)
+	b.endpoints["a:1"].inflight = 1
+
+	if got := b.Pick(); got != "b:1" {
+		t.Fatalf("Pick() = %q, want %q (fewer in-flight)", got, "b:1")
+	}
+}
+
+func TestBalancer_DoneDecrementsInflight(t *testing.T) {
+	b := New([]string{"a:1"})
+
+	addr := b.Pick()
+	if got := b.endpoints[addr].inflight; got != 1 {
+		t.Fatalf("inflight after Pick() = %d, want 1", got)
+	}
+
+	b.Done(addr, 10*time.Millisecond)
+	if got := b.endpoints[addr].inflight; got != 0 {
+		t.Fatalf("inflight after Done() = %d, want 0", got)
+	}
+}
+
+func TestBalancer_StaleEndpointResetsEWMA(t *testing.T) {
+	b := New([]string{"a:1"})
+
+	b.Done("a:1", 500*time.Millisecond)
+	if got := b.endpoints["a:1"].ewmaLatencyMs; got == 0 {
+		t.Fatalf("ewmaLatencyMs = %v, want > 0 after Done()", got)
+	}
+
+	// Backdate lastUpdate past staleAfter to simulate inactivity.
+	b.endpoints["a:1"].lastUpdate = time.Now().Add(-2 * staleAfter)
+
+	b.Pick()
+	if got := b.endpoints["a:1"].ewmaLatencyMs; got != 0 {
+		t.Fatalf("ewmaLatencyMs after stale Pick() = %v, want 0", got)
+	}
+}
+
+func TestBalancer_Addrs(t *testing.T) {
+	addrs := []string{"a:1", "b:1", "c:1"}
+	b := New(addrs)
+
+	got := b.Addrs()
+	if len(got) != len(addrs) {
+		t.Fatalf("Addrs() returned %d entries, want %d", len(got), len(addrs))
+	}
+	for i, addr := range addrs {
+		if got[i] != addr {
+			t.Fatalf("Addrs()[%d] = %q, want %q", i, got[i], addr)
+		}
+	}
+}